@@ -0,0 +1,70 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Age is how long ago the application was built. Always 0 if the build date
+// is not set.
+func Age() time.Duration {
+	return AgeAt(time.Now())
+}
+
+// AgeAt is how long before the given time the application was built. Always
+// 0 if the build date is not set. Useful for testing with a fixed clock.
+func AgeAt(now time.Time) time.Duration {
+	return ageAt(dateParsed, now)
+}
+
+func ageAt(date *time.Time, now time.Time) time.Duration {
+	if date == nil {
+		return 0
+	}
+
+	return now.Sub(*date)
+}
+
+// BuiltBefore is whether the application was built before the given time.
+// Always false if the build date is not set.
+func BuiltBefore(t time.Time) bool {
+	return dateParsed != nil && dateParsed.Before(t)
+}
+
+// WarnIfOlderThan writes a warning to the given writer if the application was
+// built longer ago than the given duration, and returns whether the warning
+// was written. Never warns if the build date is not set.
+func WarnIfOlderThan(d time.Duration, w io.Writer) bool {
+	return WarnIfOlderThanAt(time.Now(), d, w)
+}
+
+// WarnIfOlderThanAt is like WarnIfOlderThan, but relative to the given time
+// rather than the current time. Useful for testing with a fixed clock.
+func WarnIfOlderThanAt(now time.Time, d time.Duration, w io.Writer) bool {
+	return warnIfOlderThan(dateParsed, now, d, w)
+}
+
+func warnIfOlderThan(date *time.Time, now time.Time, d time.Duration, w io.Writer) bool {
+	if date == nil {
+		return false
+	}
+
+	age := ageAt(date, now)
+	if age <= d {
+		return false
+	}
+
+	const day = 24 * time.Hour
+
+	fmt.Fprintf(w, "warning: %s was built %d days ago (%s), consider upgrading\n",
+		NameOr(filepath.Base(os.Args[0])), int(age/day), date.Format("2006-01-02"))
+
+	return true
+}
@@ -0,0 +1,92 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAge(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	now := date.Add(72 * time.Hour)
+
+	if actual := ageAt(&date, now); actual != 72*time.Hour {
+		t.Fatalf("expected %v but got %v", 72*time.Hour, actual)
+	}
+
+	if actual := ageAt(nil, now); actual != 0 {
+		t.Fatalf("expected %v but got %v", 0, actual)
+	}
+}
+
+func TestWarnIfOlderThan(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	now := date.Add(45 * 24 * time.Hour)
+
+	tests := []struct {
+		date     *time.Time
+		d        time.Duration
+		expected string
+	}{
+		{
+			date: nil,
+			d:    0,
+		},
+		{
+			date: &date,
+			d:    90 * 24 * time.Hour,
+		},
+		{
+			date:     &date,
+			d:        30 * 24 * time.Hour,
+			expected: "was built 45 days ago (2019-08-23), consider upgrading\n",
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+			warned := warnIfOlderThan(test.date, now, test.d, &builder)
+
+			if warned != (test.expected != "") {
+				t.Fatalf("expected warning %v but got %v", test.expected != "", warned)
+			}
+
+			if !strings.HasSuffix(builder.String(), test.expected) {
+				t.Fatalf("expected %q to end with %q", builder.String(), test.expected)
+			}
+
+			if warned && !strings.Contains(builder.String(), NameOr(filepath.Base(os.Args[0]))) {
+				t.Fatalf("expected %q to contain the application name", builder.String())
+			}
+		})
+	}
+}
+
+func TestBuiltBefore(t *testing.T) {
+	t.Parallel()
+
+	// The date is not set when running tests, so no time is ever after it.
+	if dateParsed == nil && BuiltBefore(time.Now()) {
+		t.Fatal("expected false when the date is not set")
+	}
+
+	if dateParsed == nil && AgeAt(time.Now()) != 0 {
+		t.Fatal("expected 0 when the date is not set")
+	}
+}
@@ -0,0 +1,471 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Command metagen prints an -ldflags string that sets the xiam.li/meta.*
+// variables, using values detected from the current git checkout and an
+// optional config file. Every value is validated before printing, so that a
+// malformed value is caught before the build even starts.
+//
+// Usage:
+//
+//	go build -ldflags "$(metagen)" .
+//	eval "$(metagen -format goflags)" && go build .
+//
+// The config file is a JSON object, keyed by short variable name:
+//
+//	{
+//	  "name": "demo-app",
+//	  "url": "https://example.com/demo"
+//	}
+//
+// Values are taken from (in increasing order of precedence) the git checkout,
+// the config file, and -set flags.
+//
+// By default, the date is the current time. For reproducible builds, the date
+// is instead taken from the SOURCE_DATE_EPOCH environment variable if set
+// (see https://reproducible-builds.org/docs/source-date-epoch), or from the
+// time of the commit when the -reproducible flag is given. In both cases,
+// xiam.li/meta.reproducible is set to true.
+//
+// The build host and user, and when running on a known CI system (GitHub
+// Actions, GitLab CI, CircleCI, Jenkins, Travis CI, Buildkite, or Azure
+// Pipelines) the CI system, build ID, and build URL, are taken from the
+// environment. These differ between builds of the same source, so they are
+// omitted for reproducible builds. Use -build-env=false to always omit them,
+// for example to avoid publishing the hostname in a release binary.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"xiam.li/meta"
+)
+
+// prefix is the common prefix for the names of all variables.
+const prefix = "xiam.li/meta."
+
+func main() {
+	if err := run(os.Args[1:], os.Getenv, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "metagen:", err)
+		os.Exit(1)
+	}
+}
+
+// setFlags is a repeatable flag that collects key=value pairs.
+type setFlags map[string]string
+
+func (s setFlags) String() string {
+	return ""
+}
+
+func (s setFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value but got %q", value)
+	}
+
+	s[key] = val
+
+	return nil
+}
+
+func run(args []string, getenv func(string) string, stdout, stderr io.Writer) error {
+	sets := setFlags{}
+
+	flags := flag.NewFlagSet("metagen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFile := flags.String("config", ".metagen.json", "path to the config file, ignored if it does not exist")
+	dir := flags.String("C", ".", "path to the git checkout")
+	format := flags.String("format", "ldflags", "output format, one of ldflags or goflags")
+	reproducible := flags.Bool("reproducible", false, "use the commit time as the date, unless SOURCE_DATE_EPOCH is set")
+	buildEnv := flags.Bool("build-env", true, "detect the build host, user, and CI system from the environment")
+	flags.Var(sets, "set", "set a variable using key=value, may be repeated")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: metagen [flags]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr, "\nVariables:")
+		for _, name := range meta.Variables() {
+			fmt.Fprintln(stderr, " ", strings.TrimPrefix(name, prefix))
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Merge values from all sources, in increasing order of precedence.
+	values, err := detect(*dir, time.Now(), getenv, *reproducible)
+	if err != nil {
+		return err
+	}
+
+	if *buildEnv && values["reproducible"] != "true" {
+		for key, value := range detectBuild(getenv, os.Hostname) {
+			values[key] = value
+		}
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range config {
+		values[key] = value
+	}
+
+	for key, value := range sets {
+		values[key] = value
+	}
+
+	if err := validate(values); err != nil {
+		return err
+	}
+
+	ldflags, err := formatLDFlags(values)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "ldflags":
+		fmt.Fprintln(stdout, ldflags)
+	case "goflags":
+		goflags, err := formatGOFLAGS(ldflags)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(stdout, goflags)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	return nil
+}
+
+// detect returns the values for all variables that can be determined from the
+// git checkout in the given directory and the environment. Variables that
+// cannot be determined are omitted.
+func detect(dir string, now time.Time, getenv func(string) string, reproducible bool) (map[string]string, error) {
+	values := make(map[string]string)
+
+	// The commit time is used for xiam.li/meta.commit_date, and for
+	// xiam.li/meta.date in reproducible builds.
+	commitTime, commitErr := gitTime(dir)
+	if commitErr == nil {
+		values["commit_date"] = commitTime
+	}
+
+	switch epoch := getenv("SOURCE_DATE_EPOCH"); {
+	case epoch != "":
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SOURCE_DATE_EPOCH must be a Unix timestamp but got %q", epoch)
+		}
+
+		values["date"] = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		values["reproducible"] = "true"
+	case reproducible:
+		if commitErr != nil {
+			return nil, fmt.Errorf("reading the commit time for a reproducible build: %w", commitErr)
+		}
+
+		values["date"] = commitTime
+		values["reproducible"] = "true"
+	default:
+		values["date"] = now.UTC().Format(time.RFC3339)
+	}
+
+	if sha, err := git(dir, "rev-parse", "HEAD"); err == nil {
+		values["sha"] = sha
+	}
+
+	// Untracked files are included, as they may affect the build too.
+	if status, err := git(dir, "status", "--porcelain"); err == nil && status != "" {
+		values["dirty"] = "true"
+	}
+
+	if version, err := git(dir, "describe", "--tags"); err == nil {
+		values["version"] = version
+	}
+
+	// Only set if the commit itself is tagged.
+	if tag, err := git(dir, "describe", "--tags", "--exact-match"); err == nil {
+		values["tag"] = tag
+	}
+
+	// Fails if HEAD is detached, like on most CI systems.
+	if branch, err := git(dir, "symbolic-ref", "--short", "--quiet", "HEAD"); err == nil {
+		values["branch"] = branch
+	}
+
+	if author, err := git(dir, "show", "-s", "--format=%an <%ae>", "HEAD"); err == nil {
+		values["commit_author"] = author
+	}
+
+	if remote, err := git(dir, "remote", "get-url", "origin"); err == nil {
+		remote = stripCredentials(remote)
+
+		if meta.Validate(prefix+"repo", remote) == nil {
+			values["repo"] = remote
+		}
+
+		// Only remotes using http:// or https:// are valid source URLs.
+		if meta.Validate(prefix+"src", remote) == nil {
+			values["src"] = remote
+		}
+	}
+
+	return values, nil
+}
+
+// ciSystem describes how to detect a CI system from the environment.
+type ciSystem struct {
+	// name is the value for xiam.li/meta.ci.
+	name string
+
+	// detect is the environment variable that is set when running on the CI
+	// system.
+	detect string
+
+	// id returns the build ID.
+	id func(getenv func(string) string) string
+
+	// url returns the build URL.
+	url func(getenv func(string) string) string
+}
+
+// env returns a function that returns the value of the given environment
+// variable.
+func env(key string) func(func(string) string) string {
+	return func(getenv func(string) string) string {
+		return getenv(key)
+	}
+}
+
+// ciSystems are the supported CI systems, in order of precedence.
+var ciSystems = []ciSystem{
+	{
+		name:   "github-actions",
+		detect: "GITHUB_ACTIONS",
+		id:     env("GITHUB_RUN_ID"),
+		url: func(getenv func(string) string) string {
+			if getenv("GITHUB_SERVER_URL") == "" || getenv("GITHUB_REPOSITORY") == "" || getenv("GITHUB_RUN_ID") == "" {
+				return ""
+			}
+
+			return getenv("GITHUB_SERVER_URL") + "/" + getenv("GITHUB_REPOSITORY") + "/actions/runs/" + getenv("GITHUB_RUN_ID")
+		},
+	},
+	{
+		name:   "gitlab",
+		detect: "GITLAB_CI",
+		id:     env("CI_PIPELINE_ID"),
+		url:    env("CI_PIPELINE_URL"),
+	},
+	{
+		name:   "circleci",
+		detect: "CIRCLECI",
+		id:     env("CIRCLE_BUILD_NUM"),
+		url:    env("CIRCLE_BUILD_URL"),
+	},
+	{
+		name:   "jenkins",
+		detect: "JENKINS_URL",
+		id:     env("BUILD_ID"),
+		url:    env("BUILD_URL"),
+	},
+	{
+		name:   "travis",
+		detect: "TRAVIS",
+		id:     env("TRAVIS_BUILD_ID"),
+		url:    env("TRAVIS_BUILD_WEB_URL"),
+	},
+	{
+		name:   "buildkite",
+		detect: "BUILDKITE",
+		id:     env("BUILDKITE_BUILD_ID"),
+		url:    env("BUILDKITE_BUILD_URL"),
+	},
+	{
+		name:   "azure-pipelines",
+		detect: "TF_BUILD",
+		id:     env("BUILD_BUILDID"),
+		url: func(getenv func(string) string) string {
+			if getenv("SYSTEM_COLLECTIONURI") == "" || getenv("SYSTEM_TEAMPROJECT") == "" || getenv("BUILD_BUILDID") == "" {
+				return ""
+			}
+
+			return getenv("SYSTEM_COLLECTIONURI") + url.PathEscape(getenv("SYSTEM_TEAMPROJECT")) +
+				"/_build/results?buildId=" + url.QueryEscape(getenv("BUILD_BUILDID"))
+		},
+	},
+}
+
+// detectBuild returns the values for the build environment variables that can
+// be determined from the environment and the given hostname function.
+// Variables that cannot be determined are omitted.
+func detectBuild(getenv func(string) string, hostname func() (string, error)) map[string]string {
+	values := make(map[string]string)
+
+	if host, err := hostname(); err == nil && host != "" {
+		values["build_host"] = host
+	}
+
+	// USERNAME is used on Windows.
+	for _, key := range []string{"USER", "USERNAME"} {
+		if user := getenv(key); user != "" {
+			values["build_user"] = user
+
+			break
+		}
+	}
+
+	for _, ci := range ciSystems {
+		if getenv(ci.detect) == "" {
+			continue
+		}
+
+		values["ci"] = ci.name
+
+		if id := ci.id(getenv); id != "" {
+			values["build_id"] = id
+		}
+
+		// A malformed URL would fail validation, so omit it instead.
+		if buildURL := ci.url(getenv); meta.Validate(prefix+"build_url", buildURL) == nil && buildURL != "" {
+			values["build_url"] = buildURL
+		}
+
+		break
+	}
+
+	return values
+}
+
+// stripCredentials removes any user info from the given http:// or https://
+// remote URL, which is often an access token on CI systems. The user of an
+// ssh:// remote is kept, as it is needed to clone.
+func stripCredentials(remote string) string {
+	parsed, err := url.Parse(remote)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.User == nil {
+		return remote
+	}
+
+	parsed.User = nil
+
+	return parsed.String()
+}
+
+// gitTime returns the time of the HEAD commit in the given directory,
+// formatted as RFC 3339 in UTC.
+func gitTime(dir string) (string, error) {
+	output, err := git(dir, "show", "-s", "--format=%ct", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	seconds, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339), nil
+}
+
+// git runs git with the given arguments in the given directory, and returns
+// its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// loadConfig reads values from the given JSON config file. A missing config
+// file is not an error.
+func loadConfig(path string) (map[string]string, error) {
+	body, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var config map[string]string
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// validate checks that every value is given for a known variable, and is not
+// malformed.
+func validate(values map[string]string) error {
+	for key, value := range values {
+		if err := meta.Validate(prefix+key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatLDFlags returns an -ldflags string that sets every given value, sorted
+// by variable name.
+func formatLDFlags(values map[string]string) (string, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		// The go command splits -ldflags into fields, which may be quoted
+		// with either single or double quotes, but cannot contain escapes.
+		field := prefix + key + "=" + values[key]
+		switch {
+		case !strings.Contains(field, "'"):
+			fields = append(fields, "-X '"+field+"'")
+		case !strings.Contains(field, `"`):
+			fields = append(fields, `-X "`+field+`"`)
+		default:
+			return "", fmt.Errorf("value for %s%s contains both single and double quotes", prefix, key)
+		}
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// formatGOFLAGS returns a shell statement that exports GOFLAGS containing the
+// given -ldflags string.
+func formatGOFLAGS(ldflags string) (string, error) {
+	// GOFLAGS is split into fields the same way as -ldflags. Single quotes
+	// are already used for the -X fields, so use double quotes here.
+	if strings.Contains(ldflags, `"`) {
+		return "", errors.New("values containing double quotes cannot be used with GOFLAGS")
+	}
+
+	goflags := `"-ldflags=` + ldflags + `"`
+
+	// Quote the whole value for the shell.
+	return "export GOFLAGS='" + strings.ReplaceAll(goflags, "'", `'\''`) + "'", nil
+}
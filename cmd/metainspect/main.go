@@ -0,0 +1,95 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Command metainspect prints the application metadata embedded in existing Go
+// binaries, without running them. See package xiam.li/meta/inspect.
+//
+// Usage:
+//
+//	metainspect [-format table|json] binary...
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"xiam.li/meta/inspect"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "metainspect:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("metainspect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "table", "output format, one of table or json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: metainspect [flags] binary...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+
+		return errors.New("no binaries given")
+	}
+
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	for index, path := range flags.Args() {
+		result, err := inspect.File(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, err := range result.Errors {
+			fmt.Fprintf(stderr, "metainspect: %s: %v\n", path, err)
+		}
+
+		if err := printResult(stdout, *format, path, index, flags.NArg(), result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printResult writes the result for a single binary in the given format. When
+// inspecting multiple binaries, table output is preceded by the binary path.
+func printResult(w io.Writer, format, path string, index, total int, result *inspect.Result) error {
+	if format == "json" {
+		return json.NewEncoder(w).Encode(result.Info)
+	}
+
+	text, err := result.Info.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	if total > 1 {
+		if index > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "%s:\n", path)
+	}
+
+	_, err = w.Write(text)
+
+	return err
+}
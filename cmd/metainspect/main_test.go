@@ -0,0 +1,78 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xiam.li/meta"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	// Build this command itself, as a binary that imports xiam.li/meta.
+	binary := filepath.Join(t.TempDir(), "metainspect")
+
+	cmd := exec.Command("go", "build", "-o", binary, "-ldflags", "-X xiam.li/meta.name=metainspect -X xiam.li/meta.version=v1.2.3", ".") //nolint:gosec,lll
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-format", "json", binary}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	var info meta.Info
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Name != "metainspect" || info.Version != "v1.2.3" {
+		t.Fatalf("unexpected metadata %+v", info)
+	}
+
+	stdout.Reset()
+	if err := run([]string{binary, binary}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if strings.HasPrefix(line, "name:") && strings.HasSuffix(line, " metainspect") {
+			count++
+		}
+	}
+
+	if count != 2 {
+		t.Fatalf("unexpected output %q", stdout.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := [][]string{
+		{},
+		{"-format", "yaml", "main.go"},
+		{"main.go"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if err := run(args, &stdout, &stderr); err == nil {
+			t.Fatalf("expected an error for %q", args)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MalformedError describes an ldflags value that could not be parsed.
+type MalformedError struct {
+	// Path is the full name of the variable, like xiam.li/meta.sha.
+	Path string
+
+	// Value is the raw value that was given for the variable.
+	Value string
+
+	// Err is the reason that the value could not be parsed.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *MalformedError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("malformed ldflags value for %s: %q", e.Path, e.Value)
+	}
+
+	return fmt.Sprintf("malformed ldflags value for %s: %q: %v", e.Path, e.Value, e.Err)
+}
+
+// Unwrap returns the reason that the value could not be parsed.
+func (e *MalformedError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// errs is the list of errors that were recorded in lenient mode.
+	errs []error
+
+	// errsMu guards errs, as errors may also be recorded at runtime, for
+	// example by passing a malformed default value to AuthorURLOr.
+	errsMu sync.Mutex
+)
+
+// recordError records the given error, so that it can be retrieved by calling
+// Errors.
+func recordError(err error) {
+	errsMu.Lock()
+	defer errsMu.Unlock()
+
+	errs = append(errs, err)
+}
+
+// Errors is the list of malformed ldflags values that were encountered while
+// running in lenient mode. Each error is a *MalformedError. Always empty in
+// strict mode, as a malformed value causes a panic instead.
+func Errors() []error {
+	errsMu.Lock()
+	defer errsMu.Unlock()
+
+	return append([]error(nil), errs...)
+}
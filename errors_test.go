@@ -0,0 +1,27 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMalformedError(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		var malformedErr *MalformedError
+		if !errors.As(recover().(error), &malformedErr) {
+			t.Fatal("expected a *MalformedError")
+		}
+
+		equalString(t, "xiam.li/meta.sha", malformedErr.Path)
+		equalString(t, "HEAD", malformedErr.Value)
+		equalString(t, `malformed ldflags value for xiam.li/meta.sha: "HEAD": must only contain hex characters`, malformedErr.Error())
+	}()
+
+	mustSHA("xiam.li/meta.sha", "HEAD")
+}
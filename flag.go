@@ -0,0 +1,94 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RegisterVersionFlag registers a -version flag on the given flag set, or on
+// flag.CommandLine if nil. When the flag is given, version information is
+// printed to stdout while parsing, and the application exits. The format of
+// the version information depends on the flag value:
+//
+//	-version        demo-app v1.2.3 (bb2fecb) built 2019-08-23T18:00:00Z
+//	-version=short  demo-app v1.2.3
+//	-version=full   one "key: value" line per field, see Info.MarshalText
+//	-version=json   a JSON object, see Info
+//
+// The short format is the built-in short template, and the name of any other
+// built-in template (like -version=gnu) can also be given, see Format.
+func RegisterVersionFlag(flags *flag.FlagSet) {
+	if flags == nil {
+		flags = flag.CommandLine
+	}
+
+	usage := "print version information and exit (or use =short, =full, or =json)"
+	flags.Var(versionFlag{output: os.Stdout, exit: os.Exit}, "version", usage)
+}
+
+// versionFlag is a flag.Value that prints version information when set.
+type versionFlag struct {
+	// output is where version information is printed.
+	output io.Writer
+
+	// exit is called after version information is printed.
+	exit func(int)
+}
+
+// IsBoolFlag allows the flag to be given without a value.
+func (versionFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (versionFlag) String() string {
+	return ""
+}
+
+func (f versionFlag) Set(value string) error {
+	var output string
+
+	switch value {
+	case "false":
+		return nil
+	case "true":
+		output = Get().Banner() + "\n"
+	case "full":
+		text, err := Get().MarshalText()
+		if err != nil {
+			return err
+		}
+
+		output = string(text)
+	case "json":
+		body, err := json.Marshal(Get())
+		if err != nil {
+			return err
+		}
+
+		output = string(body) + "\n"
+	default:
+		// Any built-in template, including short, see Format.
+		if _, ok := templates[value]; !ok {
+			return fmt.Errorf("unknown format %q", value)
+		}
+
+		formatted, err := Format(value)
+		if err != nil {
+			return err
+		}
+
+		output = formatted
+	}
+
+	fmt.Fprint(f.output, output)
+	f.exit(0)
+
+	return nil
+}
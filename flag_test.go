@@ -0,0 +1,134 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestRegisterVersionFlag(t *testing.T) {
+	t.Parallel()
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterVersionFlag(flags)
+
+	if flags.Lookup("version") == nil {
+		t.Fatal("expected a -version flag")
+	}
+}
+
+func TestVersionFlag(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	tests := []struct {
+		args     []string
+		exits    bool
+		err      bool
+		json     bool
+		expected string
+	}{
+		{
+			args: []string{},
+		},
+		{
+			args: []string{"-version=false"},
+		},
+		{
+			args:     []string{"-version"},
+			exits:    true,
+			expected: Get().Banner() + "\n",
+		},
+		{
+			args:     []string{"-version=short"},
+			exits:    true,
+			expected: mustFormat(t, "short"),
+		},
+		{
+			args:     []string{"-version=full"},
+			exits:    true,
+			expected: mustMarshalText(t),
+		},
+		{
+			args:  []string{"-version=json"},
+			exits: true,
+			json:  true,
+		},
+		{
+			args:     []string{"-version=npm-style"},
+			exits:    true,
+			expected: Name() + "@" + Version() + "\n",
+		},
+		{
+			args: []string{"-version=yaml"},
+			err:  true,
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				output bytes.Buffer
+				exited bool
+			)
+
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			flags.Var(versionFlag{output: &output, exit: func(int) { exited = true }}, "version", "")
+
+			err := flags.Parse(test.args)
+
+			switch {
+			case err != nil && !test.err:
+				t.Fatal(err)
+			case err == nil && test.err:
+				t.Fatal("expected an error")
+			case exited != test.exits:
+				t.Fatalf("expected exit %v but got %v", test.exits, exited)
+			case test.expected != "":
+				equalString(t, test.expected, output.String())
+			}
+
+			if test.json {
+				var info Info
+				if err := json.Unmarshal(output.Bytes(), &info); err != nil {
+					t.Fatal(err)
+				}
+
+				equalString(t, Version(), info.Version)
+			}
+		})
+	}
+}
+
+func mustFormat(t *testing.T, tmpl string) string {
+	t.Helper()
+
+	formatted, err := Format(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return formatted
+}
+
+func mustMarshalText(t *testing.T) string {
+	t.Helper()
+
+	text, err := Get().MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(text)
+}
@@ -0,0 +1,371 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"encoding/json"
+	u "net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info is a snapshot of all application metadata. It is intended to be
+// serialized, for example as the response body of a /version endpoint or as
+// the output of a --version flag, so that the same field names are used
+// everywhere.
+type Info struct {
+	Name              string            `json:"name" yaml:"name"`
+	Title             string            `json:"title" yaml:"title"`
+	Description       string            `json:"description" yaml:"description"`
+	Author            string            `json:"author" yaml:"author"`
+	AuthorEmail       string            `json:"author_email" yaml:"author_email"`
+	AuthorURL         string            `json:"author_url" yaml:"author_url"`
+	Copyright         string            `json:"copyright" yaml:"copyright"`
+	License           string            `json:"license" yaml:"license"`
+	LicenseURL        string            `json:"license_url" yaml:"license_url"`
+	URL               string            `json:"url" yaml:"url"`
+	Docs              string            `json:"docs" yaml:"docs"`
+	Source            string            `json:"source" yaml:"source"`
+	Repo              string            `json:"repo" yaml:"repo"`
+	Branch            string            `json:"branch" yaml:"branch"`
+	Tag               string            `json:"tag" yaml:"tag"`
+	CommitAuthor      string            `json:"commit_author" yaml:"commit_author"`
+	CommitAuthorEmail string            `json:"commit_author_email" yaml:"commit_author_email"`
+	BuildHost         string            `json:"build_host" yaml:"build_host"`
+	BuildUser         string            `json:"build_user" yaml:"build_user"`
+	CI                string            `json:"ci" yaml:"ci"`
+	BuildID           string            `json:"build_id" yaml:"build_id"`
+	BuildURL          string            `json:"build_url" yaml:"build_url"`
+	Note              string            `json:"note" yaml:"note"`
+	Date              *time.Time        `json:"date" yaml:"date"`
+	CommitDate        *time.Time        `json:"commit_date" yaml:"commit_date"`
+	Reproducible      bool              `json:"reproducible" yaml:"reproducible"`
+	Development       bool              `json:"development" yaml:"development"`
+	Dirty             bool              `json:"dirty" yaml:"dirty"`
+	Version           string            `json:"version" yaml:"version"`
+	VersionMajor      string            `json:"version_major" yaml:"version_major"`
+	VersionMinor      string            `json:"version_minor" yaml:"version_minor"`
+	VersionPatch      string            `json:"version_patch" yaml:"version_patch"`
+	VersionPreRelease string            `json:"version_pre_release" yaml:"version_pre_release"`
+	VersionBuild      string            `json:"version_build" yaml:"version_build"`
+	DescribeTag       string            `json:"describe_tag" yaml:"describe_tag"`
+	CommitsSinceTag   int               `json:"commits_since_tag" yaml:"commits_since_tag"`
+	SHA               string            `json:"sha" yaml:"sha"`
+	ShortSHA          string            `json:"short_sha" yaml:"short_sha"`
+	SHAAlgorithm      string            `json:"sha_algorithm" yaml:"sha_algorithm"`
+	Go                string            `json:"go" yaml:"go"`
+	OS                string            `json:"os" yaml:"os"`
+	Arch              string            `json:"arch" yaml:"arch"`
+	Extra             map[string]string `json:"extra" yaml:"extra"`
+}
+
+// Get returns a snapshot of all application metadata.
+func Get() Info {
+	return Info{
+		Name:              Name(),
+		Title:             Title(),
+		Description:       Description(),
+		Author:            Author(),
+		AuthorEmail:       AuthorEmail(),
+		AuthorURL:         urlString(AuthorURL()),
+		Copyright:         Copyright(),
+		License:           License(),
+		LicenseURL:        urlString(LicenseURL()),
+		URL:               urlString(URL()),
+		Docs:              urlString(Docs()),
+		Source:            urlString(Source()),
+		Repo:              urlString(Repo()),
+		Branch:            Branch(),
+		Tag:               Tag(),
+		CommitAuthor:      CommitAuthor(),
+		CommitAuthorEmail: CommitAuthorEmail(),
+		BuildHost:         BuildHost(),
+		BuildUser:         BuildUser(),
+		CI:                CI(),
+		BuildID:           BuildID(),
+		BuildURL:          urlString(BuildURL()),
+		Note:              Note(),
+		Date:              Date(),
+		CommitDate:        CommitDate(),
+		Reproducible:      Reproducible(),
+		Development:       Development(),
+		Dirty:             Dirty(),
+		Version:           Version(),
+		VersionMajor:      VersionMajor(),
+		VersionMinor:      VersionMinor(),
+		VersionPatch:      VersionPatch(),
+		VersionPreRelease: VersionPreRelease(),
+		VersionBuild:      VersionBuild(),
+		DescribeTag:       DescribeTag(),
+		CommitsSinceTag:   CommitsSinceTag(),
+		SHA:               SHA(),
+		ShortSHA:          ShortSHA(),
+		SHAAlgorithm:      SHAAlgorithm(),
+		Go:                Go(),
+		OS:                OS(),
+		Arch:              Arch(),
+		Extra:             ExtraAll(),
+	}
+}
+
+// urlString returns the given URL as a string, or an empty string if nil.
+func urlString(url *u.URL) string {
+	if url == nil {
+		return ""
+	}
+
+	return url.String()
+}
+
+// plainInfo has the same fields as Info, but none of its methods. It is used
+// to serialize Info as an object rather than as the output of MarshalText.
+type plainInfo Info
+
+// MarshalJSON serializes the snapshot as a JSON object.
+func (i Info) MarshalJSON() ([]byte, error) {
+	return json.Marshal(plainInfo(i))
+}
+
+// MarshalYAML returns the snapshot in a form that YAML encoders (like
+// gopkg.in/yaml.v3) serialize as a mapping.
+func (i Info) MarshalYAML() (interface{}, error) {
+	return plainInfo(i), nil
+}
+
+// MarshalText serializes the snapshot as human-readable text, with one
+// "key: value" line per field. Fields that are not set are omitted, except
+// for boolean fields (like development), which are always printed as true or
+// false.
+func (i Info) MarshalText() ([]byte, error) {
+	// Keep only the fields that are set, and find the longest key among them
+	// so that all values can be aligned.
+	var fields []Field
+	var width int
+	for _, field := range i.Fields() {
+		if field.Value == "" {
+			continue
+		}
+
+		fields = append(fields, field)
+		if len(field.Key) > width {
+			width = len(field.Key)
+		}
+	}
+
+	var builder strings.Builder
+	for _, field := range fields {
+		builder.WriteString(field.Key)
+		builder.WriteString(":")
+		builder.WriteString(strings.Repeat(" ", width-len(field.Key)+1))
+		builder.WriteString(field.Value)
+		builder.WriteString("\n")
+	}
+
+	return []byte(builder.String()), nil
+}
+
+// Banner returns a single line of version information, like:
+//
+//	demo-app v1.2.3 (bb2fecb) built 2019-08-23T18:00:00Z
+//
+// The name defaults to the executable name if not set. The short SHA has a
+// -dirty suffix if the working tree had uncommitted changes, see Revision.
+func (i Info) Banner() string {
+	name := i.Name
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+
+	parts := []string{name}
+
+	if i.Version != "" {
+		parts = append(parts, i.Version)
+	}
+
+	if revision := i.Revision(); revision != "" {
+		parts = append(parts, "("+revision+")")
+	}
+
+	if i.Date != nil {
+		parts = append(parts, "built", i.Date.Format(time.RFC3339))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Revision is the short SHA, with a -dirty suffix if the working tree had
+// uncommitted changes, like bb2fecb-dirty. See meta.Revision.
+func (i Info) Revision() string {
+	return revision(i.ShortSHA, i.Dirty)
+}
+
+// Field is a single snapshot field, named after its JSON field name, with its
+// value formatted as a string.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Fields returns every snapshot field in a stable order. Values that are not
+// set are returned as empty strings. The extra key/value pairs are returned as
+// a single field, formatted as key=value separated by semicolons.
+func (i Info) Fields() []Field {
+	var date, commitDate, commitsSinceTag string
+	if i.Date != nil {
+		date = i.Date.Format(time.RFC3339)
+	}
+
+	if i.CommitDate != nil {
+		commitDate = i.CommitDate.Format(time.RFC3339)
+	}
+
+	if i.DescribeTag != "" {
+		commitsSinceTag = strconv.Itoa(i.CommitsSinceTag)
+	}
+
+	return []Field{
+		{"name", i.Name},
+		{"title", i.Title},
+		{"description", i.Description},
+		{"author", i.Author},
+		{"author_email", i.AuthorEmail},
+		{"author_url", i.AuthorURL},
+		{"copyright", i.Copyright},
+		{"license", i.License},
+		{"license_url", i.LicenseURL},
+		{"url", i.URL},
+		{"docs", i.Docs},
+		{"source", i.Source},
+		{"repo", i.Repo},
+		{"branch", i.Branch},
+		{"tag", i.Tag},
+		{"commit_author", i.CommitAuthor},
+		{"commit_author_email", i.CommitAuthorEmail},
+		{"build_host", i.BuildHost},
+		{"build_user", i.BuildUser},
+		{"ci", i.CI},
+		{"build_id", i.BuildID},
+		{"build_url", i.BuildURL},
+		{"note", i.Note},
+		{"date", date},
+		{"commit_date", commitDate},
+		{"reproducible", strconv.FormatBool(i.Reproducible)},
+		{"development", strconv.FormatBool(i.Development)},
+		{"dirty", strconv.FormatBool(i.Dirty)},
+		{"version", i.Version},
+		{"version_major", i.VersionMajor},
+		{"version_minor", i.VersionMinor},
+		{"version_patch", i.VersionPatch},
+		{"version_pre_release", i.VersionPreRelease},
+		{"version_build", i.VersionBuild},
+		{"describe_tag", i.DescribeTag},
+		{"commits_since_tag", commitsSinceTag},
+		{"sha", i.SHA},
+		{"short_sha", i.ShortSHA},
+		{"sha_algorithm", i.SHAAlgorithm},
+		{"go", i.Go},
+		{"os", i.OS},
+		{"arch", i.Arch},
+		{"extra", formatExtra(i.Extra)},
+	}
+}
+
+// Parse returns a snapshot of the metadata given by the raw values of the
+// named variables (like xiam.li/meta.sha), as if they were given with
+// ldflags. This is useful for metadata that was read from elsewhere, like
+// another binary. The Go, OS, and Arch fields are not set. Malformed values
+// are treated as if they were not set, and are returned as errors.
+func Parse(values map[string]string) (Info, []error) {
+	var errs []error
+
+	check := func(path string) string {
+		raw := values[path]
+		if err := Validate(path, raw); err != nil {
+			errs = append(errs, err)
+
+			return ""
+		}
+
+		return raw
+	}
+
+	parsedURL := func(path string) string {
+		parsed, _ := parseURL(check(path))
+
+		return urlString(parsed)
+	}
+
+	author, authorEmail := mustAuthor("", values["xiam.li/meta.author"])
+	commitAuthor, commitAuthorEmail := mustAuthor("", values["xiam.li/meta.commit_author"])
+	repo, _ := parseRepo(check("xiam.li/meta.repo"))
+	extra, _ := parseExtra(check("xiam.li/meta.extra"))
+	if extra == nil {
+		extra = make(map[string]string)
+	}
+	date, _ := parseTime(check("xiam.li/meta.date"))
+	commitDate, _ := parseTime(check("xiam.li/meta.commit_date"))
+	sha, _ := parseSHA(check("xiam.li/meta.sha"))
+
+	shortSHALength, err := parseShortSHALength(check("xiam.li/meta.short_sha_length"))
+	if err != nil {
+		shortSHALength = defaultShortSHALength
+	}
+	version := values["xiam.li/meta.version"]
+
+	describeTag, commitsSinceTag, describeSHA := parseDescribe(version)
+	if err := checkDescribe(describeSHA, sha); err != nil {
+		errs = append(errs, &MalformedError{Path: "xiam.li/meta.version", Value: version, Err: err})
+	}
+
+	versionMajor, versionMinor, versionPatch, versionPreRelease, versionBuild := mustSemver("", describeTag)
+
+	info := Info{
+		Name:              values["xiam.li/meta.name"],
+		Title:             values["xiam.li/meta.title"],
+		Description:       values["xiam.li/meta.desc"],
+		Author:            author,
+		AuthorEmail:       authorEmail,
+		AuthorURL:         parsedURL("xiam.li/meta.author_url"),
+		Copyright:         values["xiam.li/meta.copyright"],
+		License:           values["xiam.li/meta.license"],
+		LicenseURL:        parsedURL("xiam.li/meta.license_url"),
+		URL:               parsedURL("xiam.li/meta.url"),
+		Docs:              parsedURL("xiam.li/meta.docs"),
+		Source:            parsedURL("xiam.li/meta.src"),
+		Repo:              urlString(repo),
+		Branch:            values["xiam.li/meta.branch"],
+		Tag:               values["xiam.li/meta.tag"],
+		CommitAuthor:      commitAuthor,
+		CommitAuthorEmail: commitAuthorEmail,
+		BuildHost:         values["xiam.li/meta.build_host"],
+		BuildUser:         values["xiam.li/meta.build_user"],
+		CI:                values["xiam.li/meta.ci"],
+		BuildID:           values["xiam.li/meta.build_id"],
+		BuildURL:          parsedURL("xiam.li/meta.build_url"),
+		Note:              values["xiam.li/meta.note"],
+		Date:              date,
+		CommitDate:        commitDate,
+		Reproducible:      mustBool("", values["xiam.li/meta.reproducible"]),
+		Development:       mustBool("", values["xiam.li/meta.dev"]),
+		Dirty:             mustBool("", values["xiam.li/meta.dirty"]),
+		Version:           version,
+		VersionMajor:      versionMajor,
+		VersionMinor:      versionMinor,
+		VersionPatch:      versionPatch,
+		VersionPreRelease: versionPreRelease,
+		VersionBuild:      versionBuild,
+		DescribeTag:       describeTag,
+		CommitsSinceTag:   commitsSinceTag,
+		SHA:               sha,
+		ShortSHA:          shortSHA(sha, shortSHALength),
+		SHAAlgorithm:      shaAlgorithm(sha),
+		Extra:             extra,
+	}
+
+	return info, errs
+}
@@ -0,0 +1,170 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	actual := Get()
+	equalString(t, runtime.GOARCH, actual.Arch)
+	equalString(t, runtime.GOOS, actual.OS)
+	equalString(t, runtime.Version(), actual.Go)
+	equalString(t, Version(), actual.Version)
+	equalString(t, SHA(), actual.SHA)
+}
+
+func TestInfoMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	info := Info{
+		Name:    "demo-app",
+		URL:     "https://example.com/page",
+		Date:    &date,
+		Version: "v1.2.3",
+		Extra:   map[string]string{"env": "production"},
+	}
+
+	body, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, "demo-app", actual["name"].(string))
+	equalString(t, "https://example.com/page", actual["url"].(string))
+	equalString(t, "2019-08-23T18:00:00Z", actual["date"].(string))
+	equalString(t, "v1.2.3", actual["version"].(string))
+	equalString(t, "", actual["sha"].(string))
+	equalString(t, "production", actual["extra"].(map[string]interface{})["env"].(string))
+
+	if len(actual) != len(info.Fields()) {
+		t.Fatalf("expected %d fields but got %d", len(info.Fields()), len(actual))
+	}
+}
+
+func TestInfoMarshalText(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	info := Info{
+		Name:    "demo-app",
+		Date:    &date,
+		Version: "v1.2.3",
+		SHA:     "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+	}
+
+	actual, err := info.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "" +
+		"name:         demo-app\n" +
+		"date:         2019-08-23T18:00:00Z\n" +
+		"reproducible: false\n" +
+		"development:  false\n" +
+		"dirty:        false\n" +
+		"version:      v1.2.3\n" +
+		"sha:          bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6\n"
+
+	equalString(t, expected, string(actual))
+}
+
+func TestInfoBanner(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		info     Info
+		expected string
+	}{
+		{
+			info:     Info{Name: "demo-app", Version: "v1.2.3", ShortSHA: "bb2fecb", Date: &date},
+			expected: "demo-app v1.2.3 (bb2fecb) built 2019-08-23T18:00:00Z",
+		},
+		{
+			info:     Info{Name: "demo-app", ShortSHA: "bb2fecb", Dirty: true},
+			expected: "demo-app (bb2fecb-dirty)",
+		},
+		{
+			info:     Info{Version: "v1.2.3"},
+			expected: filepath.Base(os.Args[0]) + " v1.2.3",
+		},
+	}
+
+	for _, test := range tests {
+		equalString(t, test.expected, test.info.Banner())
+	}
+}
+
+func TestInfoRevision(t *testing.T) {
+	t.Parallel()
+
+	equalString(t, "bb2fecb", Info{ShortSHA: "bb2fecb"}.Revision())
+	equalString(t, "bb2fecb-dirty", Info{ShortSHA: "bb2fecb", Dirty: true}.Revision())
+	equalString(t, "", Info{Dirty: true}.Revision())
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	actual, errs := Parse(map[string]string{
+		"xiam.li/meta.author":       "Jane Doe <jdoe@example.com>",
+		"xiam.li/meta.branch":       "main",
+		"xiam.li/meta.commit_date":  "1566583200",
+		"xiam.li/meta.date":         "Fri, 23 Aug 2019 11:00:00 -0700",
+		"xiam.li/meta.dev":          "true",
+		"xiam.li/meta.name":         "demo-app",
+		"xiam.li/meta.repo":         "git@example.com:demo.git",
+		"xiam.li/meta.reproducible": "true",
+		"xiam.li/meta.sha":          "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+		"xiam.li/meta.url":          "example.com/page",
+		"xiam.li/meta.src":          "https://example.com/page",
+		"xiam.li/meta.version":      "v1.2.3-rc.456",
+	})
+
+	expectedDate := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+
+	equalString(t, "Jane Doe", actual.Author)
+	equalString(t, "jdoe@example.com", actual.AuthorEmail)
+	equalTime(t, &expectedDate, actual.Date)
+	equalTime(t, &expectedDate, actual.CommitDate)
+	equalString(t, "demo-app", actual.Name)
+	equalString(t, "bb2fecb", actual.ShortSHA)
+	equalString(t, "", actual.URL)
+	equalString(t, "https://example.com/page", actual.Source)
+	equalString(t, "ssh://git@example.com/demo.git", actual.Repo)
+	equalString(t, "main", actual.Branch)
+	equalString(t, "rc.456", actual.VersionPreRelease)
+	equalString(t, "v1.2.3-rc.456", actual.DescribeTag)
+	equalString(t, "", actual.Go)
+
+	if !actual.Development {
+		t.Fatal("expected development to be true")
+	}
+
+	if !actual.Reproducible {
+		t.Fatal("expected reproducible to be true")
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error but got %v", errs)
+	}
+}
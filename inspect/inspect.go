@@ -0,0 +1,448 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package inspect reads the application metadata embedded in an existing Go
+// binary, without running it. ELF (Linux), Mach-O (macOS), and PE (Windows)
+// binaries are supported.
+//
+// Values are read from the xiam.li/meta.* variables in the binary symbol
+// table. If the binary was stripped (for example with -ldflags=-s), or if a
+// variable was removed by the linker because it is never used, values are
+// instead read from the -ldflags build setting that the Go toolchain embeds
+// into the binary. In both cases, the build information embedded by the Go
+// toolchain is used as a fallback, like at runtime.
+package inspect
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"xiam.li/meta"
+)
+
+// prefix is the common prefix for the names of all variables.
+const prefix = "xiam.li/meta."
+
+// Result is the application metadata read from a binary.
+type Result struct {
+	// Info is the metadata snapshot, as it would be returned by meta.Get()
+	// when running the binary on its target platform.
+	Info meta.Info
+
+	// Variables are the raw values of all variables that were set, keyed by
+	// full variable name, like xiam.li/meta.sha.
+	Variables map[string]string
+
+	// Errors are any malformed values that were found. The binary would
+	// panic when run, unless it was built with lenient mode enabled.
+	Errors []error
+}
+
+// File reads the application metadata embedded in the binary at the given
+// path.
+func File(path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+// Read reads the application metadata embedded in the given binary.
+func Read(r io.ReaderAt) (*Result, error) {
+	info, err := buildinfo.Read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string, len(info.Settings))
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	// Start with the values from the -ldflags setting. These are needed for
+	// stripped binaries, and for variables that were removed by the linker
+	// because the binary never uses them.
+	variables := parseLDFlags(settings["-ldflags"])
+
+	symbols, err := readSymbols(r)
+	if err != nil && !errors.Is(err, errNoSymbols) {
+		return nil, err
+	}
+
+	for key, value := range symbols {
+		variables[key] = value
+	}
+
+	parsed, errs := meta.Parse(withFallback(variables, settings, info.Main.Version))
+	parsed.Go = info.GoVersion
+	parsed.OS = settings["GOOS"]
+	parsed.Arch = settings["GOARCH"]
+
+	return &Result{
+		Info:      parsed,
+		Variables: variables,
+		Errors:    errs,
+	}, nil
+}
+
+// withFallback returns the given variables, with values that are not set
+// taken from the build information embedded by the Go toolchain, like at
+// runtime.
+func withFallback(variables, settings map[string]string, mainVersion string) map[string]string {
+	values := make(map[string]string, len(variables))
+	for key, value := range variables {
+		values[key] = value
+	}
+
+	// A date taken from vcs.time is the commit time, which is a source
+	// timestamp, so the build is reproducible (see meta.Reproducible).
+	if values[prefix+"date"] == "" && settings["vcs.time"] != "" {
+		values[prefix+"reproducible"] = "true"
+	}
+
+	fallback := map[string]string{
+		prefix + "commit_date": settings["vcs.time"],
+		prefix + "date":        settings["vcs.time"],
+		prefix + "dirty":       settings["vcs.modified"],
+		prefix + "sha":         settings["vcs.revision"],
+	}
+
+	if mainVersion != "(devel)" {
+		fallback[prefix+"version"] = mainVersion
+	}
+
+	for key, value := range fallback {
+		if values[key] == "" {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+// errNoSymbols is returned when a binary has no symbol table.
+var errNoSymbols = errors.New("no symbol table")
+
+// symbol is a symbol from a binary symbol table.
+type symbol struct {
+	name string
+	addr uint64
+}
+
+// image is the parts of a binary needed to read string variables.
+type image struct {
+	symbols []symbol
+	order   binary.ByteOrder
+	ptrSize int
+
+	// read reads size bytes at the given virtual address.
+	read func(addr, size uint64) ([]byte, error)
+}
+
+// readSymbols reads the values of all xiam.li/meta.* string variables from
+// the symbol table of the given binary.
+func readSymbols(r io.ReaderAt) (map[string]string, error) {
+	img, err := open(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(img.symbols) == 0 {
+		return nil, errNoSymbols
+	}
+
+	known := make(map[string]bool)
+	for _, name := range meta.Variables() {
+		known[name] = true
+	}
+
+	variables := make(map[string]string)
+
+	for _, sym := range img.symbols {
+		// Mach-O symbols are prefixed with an underscore.
+		name := strings.TrimPrefix(sym.name, "_")
+		if !known[name] {
+			continue
+		}
+
+		value, err := img.readString(sym.addr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+
+		if value != "" {
+			variables[name] = value
+		}
+	}
+
+	return variables, nil
+}
+
+// readString reads a Go string, whose header (a pointer and length) is at the
+// given virtual address.
+func (img image) readString(addr uint64) (string, error) {
+	header, err := img.read(addr, uint64(2*img.ptrSize))
+	if err != nil {
+		// Unset variables live in sections without file data, like .bss.
+		return "", nil //nolint:nilerr
+	}
+
+	var ptr, length uint64
+	if img.ptrSize == 8 { //nolint:gomnd
+		ptr, length = img.order.Uint64(header), img.order.Uint64(header[8:])
+	} else {
+		ptr, length = uint64(img.order.Uint32(header)), uint64(img.order.Uint32(header[4:]))
+	}
+
+	if ptr == 0 || length == 0 {
+		return "", nil
+	}
+
+	data, err := img.read(ptr, length)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// section is a section from a binary, mapped at a virtual address.
+type section struct {
+	addr   uint64
+	size   uint64
+	reader io.ReaderAt
+}
+
+// readSections returns a function that reads from the section containing a
+// virtual address.
+func readSections(sections []section) func(addr, size uint64) ([]byte, error) {
+	return func(addr, size uint64) ([]byte, error) {
+		for _, s := range sections {
+			// Compared without adding to addr, as a corrupt size could
+			// overflow.
+			if s.reader == nil || addr < s.addr || size > s.size || addr-s.addr > s.size-size {
+				continue
+			}
+
+			data := make([]byte, size)
+			if _, err := s.reader.ReadAt(data, int64(addr-s.addr)); err != nil {
+				return nil, err
+			}
+
+			return data, nil
+		}
+
+		return nil, fmt.Errorf("address %#x is not mapped to file data", addr)
+	}
+}
+
+// open parses the given binary as ELF, Mach-O, or PE.
+func open(r io.ReaderAt) (image, error) {
+	if f, err := elf.NewFile(r); err == nil {
+		return openELF(f)
+	}
+
+	if f, err := macho.NewFile(r); err == nil {
+		return openMachO(f)
+	}
+
+	if f, err := pe.NewFile(r); err == nil {
+		return openPE(f)
+	}
+
+	return image{}, errors.New("unsupported binary format")
+}
+
+func openELF(f *elf.File) (image, error) {
+	img := image{
+		order:   f.ByteOrder,
+		ptrSize: 8, //nolint:gomnd
+	}
+
+	if f.Class == elf.ELFCLASS32 {
+		img.ptrSize = 4
+	}
+
+	syms, err := f.Symbols()
+	if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
+		return image{}, err
+	}
+
+	for _, sym := range syms {
+		img.symbols = append(img.symbols, symbol{name: sym.Name, addr: sym.Value})
+	}
+
+	sections := make([]section, 0, len(f.Sections))
+	for _, s := range f.Sections {
+		if s.Type == elf.SHT_NOBITS {
+			continue
+		}
+
+		sections = append(sections, section{addr: s.Addr, size: s.Size, reader: s})
+	}
+
+	img.read = readSections(sections)
+
+	return img, nil
+}
+
+func openMachO(f *macho.File) (image, error) {
+	img := image{
+		order:   f.ByteOrder,
+		ptrSize: 8, //nolint:gomnd
+	}
+
+	if f.Magic == macho.Magic32 {
+		img.ptrSize = 4
+	}
+
+	if f.Symtab != nil {
+		for _, sym := range f.Symtab.Syms {
+			img.symbols = append(img.symbols, symbol{name: sym.Name, addr: sym.Value})
+		}
+	}
+
+	sections := make([]section, 0, len(f.Sections))
+	for _, s := range f.Sections {
+		// Zero-filled sections (like __bss) have no file data.
+		const zerofill = 0x1
+		if s.Flags&0xff == zerofill {
+			continue
+		}
+
+		sections = append(sections, section{addr: s.Addr, size: s.Size, reader: s})
+	}
+
+	img.read = readSections(sections)
+
+	return img, nil
+}
+
+func openPE(f *pe.File) (image, error) {
+	img := image{
+		order:   binary.LittleEndian,
+		ptrSize: 8, //nolint:gomnd
+	}
+
+	var imageBase uint64
+	switch header := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		imageBase = uint64(header.ImageBase)
+		img.ptrSize = 4
+	case *pe.OptionalHeader64:
+		imageBase = header.ImageBase
+	}
+
+	// PE symbol values are relative to the start of their section.
+	for _, sym := range f.Symbols {
+		if sym.SectionNumber <= 0 || int(sym.SectionNumber) > len(f.Sections) {
+			continue
+		}
+
+		s := f.Sections[sym.SectionNumber-1]
+		img.symbols = append(img.symbols, symbol{
+			name: sym.Name,
+			addr: imageBase + uint64(s.VirtualAddress) + uint64(sym.Value),
+		})
+	}
+
+	sections := make([]section, 0, len(f.Sections))
+	for _, s := range f.Sections {
+		// Only the part of a section backed by file data can be read.
+		size := s.VirtualSize
+		if s.Size < size {
+			size = s.Size
+		}
+
+		sections = append(sections, section{
+			addr:   imageBase + uint64(s.VirtualAddress),
+			size:   uint64(size),
+			reader: s,
+		})
+	}
+
+	img.read = readSections(sections)
+
+	return img, nil
+}
+
+// parseLDFlags returns the values of all xiam.li/meta.* variables set with -X
+// in the given -ldflags value.
+func parseLDFlags(ldflags string) map[string]string {
+	variables := make(map[string]string)
+	fields := splitQuoted(ldflags)
+
+	for i := 0; i < len(fields); i++ {
+		var definition string
+
+		switch {
+		case fields[i] == "-X" || fields[i] == "--X":
+			if i+1 < len(fields) {
+				i++
+				definition = fields[i]
+			}
+		case strings.HasPrefix(fields[i], "-X="):
+			definition = strings.TrimPrefix(fields[i], "-X=")
+		case strings.HasPrefix(fields[i], "--X="):
+			definition = strings.TrimPrefix(fields[i], "--X=")
+		}
+
+		name, value, ok := strings.Cut(definition, "=")
+		if ok && strings.HasPrefix(name, prefix) && value != "" {
+			variables[name] = value
+		}
+	}
+
+	return variables
+}
+
+// splitQuoted splits the given value into fields separated by whitespace,
+// where fields may be quoted with single or double quotes, the same way that
+// the go command splits -ldflags.
+func splitQuoted(s string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+		quote   rune
+		inField bool
+	)
+
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+
+	if inField {
+		fields = append(fields, current.String())
+	}
+
+	return fields
+}
@@ -0,0 +1,262 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package inspect
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// build builds the demo program for the given platform with the given
+// ldflags, and returns the path of the resulting binary.
+func build(t *testing.T, goos, goarch, ldflags string) string {
+	t.Helper()
+
+	output := filepath.Join(t.TempDir(), "demo")
+
+	cmd := exec.Command("go", "build", "-o", output, "-ldflags", ldflags, "./testdata/demo") //nolint:gosec
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+
+	if combined, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, combined)
+	}
+
+	return output
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	const ldflags = "-X 'xiam.li/meta.name=demo app' " +
+		"-X xiam.li/meta.version=v1.2.3 " +
+		"-X xiam.li/meta.sha=bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6 " +
+		"-X 'xiam.li/meta.date=Fri, 23 Aug 2019 11:00:00 -0700'"
+
+	tests := []struct {
+		goos    string
+		goarch  string
+		ldflags string
+	}{
+		{goos: "linux", goarch: "amd64", ldflags: ldflags},
+		{goos: "linux", goarch: "386", ldflags: ldflags},
+		{goos: "darwin", goarch: "arm64", ldflags: ldflags},
+		{goos: "windows", goarch: "amd64", ldflags: ldflags},
+		{goos: "windows", goarch: "386", ldflags: ldflags},
+		// Stripped binaries fall back to the -ldflags build setting.
+		{goos: "linux", goarch: "amd64", ldflags: "-s -w " + ldflags},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprintf("%s/%s %s", test.goos, test.goarch, test.ldflags[:6]), func(t *testing.T) {
+			t.Parallel()
+
+			result, err := File(build(t, test.goos, test.goarch, test.ldflags))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			equalString(t, "demo app", result.Info.Name)
+			equalString(t, "v1.2.3", result.Info.Version)
+			equalString(t, "3", result.Info.VersionPatch)
+			equalString(t, "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6", result.Info.SHA)
+			equalString(t, "2019-08-23T18:00:00Z", result.Info.Date.Format("2006-01-02T15:04:05Z07:00"))
+			equalString(t, test.goos, result.Info.OS)
+			equalString(t, test.goarch, result.Info.Arch)
+			equalString(t, "v1.2.3", result.Variables["xiam.li/meta.version"])
+
+			if result.Info.Go == "" {
+				t.Fatal("expected a Go version")
+			}
+
+			if len(result.Errors) != 0 {
+				t.Fatalf("expected no errors but got %v", result.Errors)
+			}
+		})
+	}
+}
+
+func TestFileUnset(t *testing.T) {
+	t.Parallel()
+
+	result, err := File(build(t, "linux", "amd64", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, "", result.Info.Name)
+
+	if len(result.Variables) != 0 {
+		t.Fatalf("expected no variables but got %v", result.Variables)
+	}
+}
+
+func TestFileVCS(t *testing.T) {
+	t.Parallel()
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := os.ReadFile("testdata/demo/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Build the demo program from its own git repository, so that the Go
+	// toolchain embeds the vcs.* build settings.
+	dir := t.TempDir()
+	goMod := "module demo\n\ngo 1.18\n\nrequire xiam.li/meta v0.0.0\n\nreplace xiam.li/meta => " + root + "\n"
+
+	for name, content := range map[string]string{"go.mod": goMod, "main.go": string(source)} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "demo")
+
+	for _, args := range [][]string{
+		{"git", "init", "--quiet"},
+		{"git", "add", "."},
+		{"git", "-c", "user.name=Jane Doe", "-c", "user.email=jdoe@example.com", "commit", "--quiet", "-m", "Initial commit"},
+		{"go", "build", "-buildvcs=true", "-o", output, "."},
+	} {
+		cmd := exec.Command(args[0], args[1:]...) //nolint:gosec
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2019-08-23T11:00:00-0700")
+
+		if combined, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %v\n%s", args, err, combined)
+		}
+	}
+
+	// Without ldflags, the date is the commit time, and both the binary and
+	// the inspected metadata report a reproducible build.
+	actual, err := exec.Command(output).Output() //nolint:gosec
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(string(actual), " true\n") {
+		t.Fatalf("expected the binary to report a reproducible build but got %q", actual)
+	}
+
+	result, err := File(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, "2019-08-23T18:00:00Z", result.Info.Date.Format("2006-01-02T15:04:05Z07:00"))
+
+	if !result.Info.Reproducible {
+		t.Fatal("expected reproducible to be true")
+	}
+}
+
+func TestWithFallback(t *testing.T) {
+	t.Parallel()
+
+	settings := map[string]string{"vcs.time": "2019-08-23T18:00:00Z", "vcs.modified": "true"}
+
+	values := withFallback(map[string]string{}, settings, "(devel)")
+	equalString(t, "2019-08-23T18:00:00Z", values["xiam.li/meta.date"])
+	equalString(t, "true", values["xiam.li/meta.reproducible"])
+	equalString(t, "true", values["xiam.li/meta.dirty"])
+	equalString(t, "", values["xiam.li/meta.dev"])
+
+	values = withFallback(map[string]string{"xiam.li/meta.date": "2021-01-02T03:04:05Z"}, settings, "(devel)")
+	equalString(t, "2021-01-02T03:04:05Z", values["xiam.li/meta.date"])
+	equalString(t, "", values["xiam.li/meta.reproducible"])
+}
+
+func TestFileMalformed(t *testing.T) {
+	t.Parallel()
+
+	result, err := File(build(t, "linux", "amd64", "-X xiam.li/meta.url=example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, "", result.Info.URL)
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected 1 error but got %v", result.Errors)
+	}
+}
+
+func TestFileNotBinary(t *testing.T) {
+	t.Parallel()
+
+	if _, err := File("inspect.go"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestReadSections(t *testing.T) {
+	t.Parallel()
+
+	read := readSections([]section{{
+		addr:   0x1000,
+		size:   8,
+		reader: strings.NewReader("abcdefgh"),
+	}})
+
+	tests := []struct {
+		addr     uint64
+		size     uint64
+		expected string
+		err      bool
+	}{
+		{addr: 0x1000, size: 3, expected: "abc"},
+		{addr: 0x1005, size: 3, expected: "fgh"},
+		{addr: 0x1005, size: 4, err: true},
+		{addr: 0xfff, size: 1, err: true},
+		{addr: 0x1001, size: math.MaxUint64, err: true},
+	}
+
+	for _, test := range tests {
+		data, err := read(test.addr, test.size)
+
+		switch {
+		case err != nil && !test.err:
+			t.Fatal(err)
+		case err == nil && test.err:
+			t.Fatalf("expected an error for %#x+%d", test.addr, test.size)
+		case err == nil:
+			equalString(t, test.expected, string(data))
+		}
+	}
+}
+
+func TestParseLDFlags(t *testing.T) {
+	t.Parallel()
+
+	actual := parseLDFlags(`-s -X 'xiam.li/meta.name=demo app' -X=xiam.li/meta.version=v1.2.3 ` +
+		`--X "xiam.li/meta.note=Jim's build" -X main.other=value`)
+
+	equalString(t, "demo app", actual["xiam.li/meta.name"])
+	equalString(t, "v1.2.3", actual["xiam.li/meta.version"])
+	equalString(t, "Jim's build", actual["xiam.li/meta.note"])
+
+	if len(actual) != 3 {
+		t.Fatalf("expected 3 variables but got %v", actual)
+	}
+}
+
+func equalString(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	if actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Command demo is built by tests with various ldflags, and then inspected.
+package main
+
+import (
+	"fmt"
+
+	"xiam.li/meta"
+)
+
+func main() {
+	fmt.Println(meta.Name(), meta.Version(), meta.Reproducible())
+}
@@ -24,21 +24,45 @@ type info struct {
 	Author            string
 	AuthorEmail       string
 	AuthorURL         *u.URL
+	Branch            string
+	BuildHost         string
+	BuildID           string
+	BuildURL          *u.URL
+	BuildUser         string
+	CI                string
+	CommitAuthor      string
+	CommitAuthorEmail string
+	CommitDate        *time.Time
+	CommitDateFormat  string
+	CommitsSinceTag   int
 	Copyright         string
 	Date              *time.Time
 	DateFormat        string
+	DescribeSHA       string
+	DescribeTag       string
 	Description       string
 	Development       bool
+	Dirty             bool
 	Docs              *u.URL
+	Errors            []string
+	ExtraAll          map[string]string
+	ExtraEnv          string
 	Go                string
 	License           string
 	LicenseURL        *u.URL
 	Name              string
 	Note              string
 	OS                string
+	Repo              *u.URL
+	Reproducible      bool
+	Revision          string
 	SHA               string
+	SHAAlgorithm      string
 	ShortSHA          string
+	ShortSHAOr        string
 	Source            *u.URL
+	Strict            bool
+	Tag               string
 	Title             string
 	URL               *u.URL
 	Version           string
@@ -53,27 +77,57 @@ type info struct {
 func TestJSON(t *testing.T) {
 	t.Parallel()
 
+	// Errors are stored as strings, as they cannot be unmarshaled.
+	var errs []string
+	for _, err := range Errors() {
+		errs = append(errs, err.Error())
+	}
+
 	// Store a value from each public function in this package.
 	info := info{
 		Arch:              Arch(),
 		Author:            Author(),
 		AuthorEmail:       AuthorEmail(),
 		AuthorURL:         AuthorURL(),
+		Branch:            Branch(),
+		BuildHost:         BuildHost(),
+		BuildID:           BuildID(),
+		BuildURL:          BuildURL(),
+		BuildUser:         BuildUser(),
+		CI:                CI(),
+		CommitAuthor:      CommitAuthor(),
+		CommitAuthorEmail: CommitAuthorEmail(),
+		CommitDate:        CommitDate(),
+		CommitDateFormat:  CommitDateFormat(time.RFC3339),
+		CommitsSinceTag:   CommitsSinceTag(),
 		Copyright:         Copyright(),
 		Date:              Date(),
 		DateFormat:        DateFormat(time.RFC3339),
+		DescribeSHA:       DescribeSHA(),
+		DescribeTag:       DescribeTag(),
 		Description:       Description(),
 		Development:       Development(),
+		Dirty:             Dirty(),
 		Docs:              Docs(),
+		Errors:            errs,
+		ExtraAll:          ExtraAll(),
+		ExtraEnv:          ExtraOr("env", "none"),
 		Go:                Go(),
 		License:           License(),
 		LicenseURL:        LicenseURL(),
 		Name:              Name(),
 		Note:              Note(),
 		OS:                OS(),
+		Repo:              Repo(),
+		Reproducible:      Reproducible(),
+		Revision:          Revision(),
 		SHA:               SHA(),
+		SHAAlgorithm:      SHAAlgorithm(),
 		ShortSHA:          ShortSHA(),
+		ShortSHAOr:        ShortSHAOr("none"),
 		Source:            Source(),
+		Strict:            Strict(),
+		Tag:               Tag(),
 		Title:             Title(),
 		URL:               URL(),
 		Version:           Version(),
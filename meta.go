@@ -11,28 +11,93 @@
 //
 //	xiam.li/meta.author
 //	xiam.li/meta.author_url
+//	xiam.li/meta.branch
+//	xiam.li/meta.build_host
+//	xiam.li/meta.build_id
+//	xiam.li/meta.build_url
+//	xiam.li/meta.build_user
+//	xiam.li/meta.ci
+//	xiam.li/meta.commit_author
+//	xiam.li/meta.commit_date
 //	xiam.li/meta.copyright
 //	xiam.li/meta.date
 //	xiam.li/meta.desc
 //	xiam.li/meta.dev
+//	xiam.li/meta.dirty
 //	xiam.li/meta.docs
+//	xiam.li/meta.extra
 //	xiam.li/meta.license
 //	xiam.li/meta.license_url
 //	xiam.li/meta.name
 //	xiam.li/meta.note
+//	xiam.li/meta.repo
+//	xiam.li/meta.reproducible
 //	xiam.li/meta.sha
+//	xiam.li/meta.short_sha_length
 //	xiam.li/meta.src
+//	xiam.li/meta.strict
+//	xiam.li/meta.tag
 //	xiam.li/meta.title
 //	xiam.li/meta.url
 //	xiam.li/meta.version
+//
+// When built with Go 1.18 or later, the xiam.li/meta.commit_date,
+// xiam.li/meta.date, xiam.li/meta.dirty, xiam.li/meta.sha, and
+// xiam.li/meta.version variables fall back to the build information embedded
+// by the Go toolchain if they are not set. Values given with ldflags
+// always take precedence. See https://pkg.go.dev/runtime/debug#ReadBuildInfo.
 package meta
 
 import (
 	u "net/url"
 	"runtime"
+	"runtime/debug"
 	"time"
 )
 
+// buildVersion and buildSettings are the main module version and the build
+// settings (like vcs.revision) that were embedded into the binary by the Go
+// toolchain.
+var buildVersion, buildSettings = readBuildInfo()
+
+// readBuildInfo returns the main module version and build settings embedded
+// into the running binary, if available.
+func readBuildInfo() (string, map[string]string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", nil
+	}
+
+	return parseBuildInfo(info)
+}
+
+// parseBuildInfo returns the main module version and build settings from the
+// given build info.
+func parseBuildInfo(info *debug.BuildInfo) (string, map[string]string) {
+	settings := make(map[string]string, len(info.Settings))
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	// Binaries built from a local checkout (as opposed to go install
+	// module@version) report their main module version as "(devel)".
+	if info.Main.Version == "(devel)" {
+		return "", settings
+	}
+
+	return info.Main.Version, settings
+}
+
+// fallback returns the given ldflags value, or the value of the named build
+// setting if not set.
+func fallback(raw, key string) string {
+	if raw != "" {
+		return raw
+	}
+
+	return buildSettings[key]
+}
+
 // Arch is the architecture target that the application is running on.
 func Arch() string {
 	return runtime.GOARCH
@@ -110,6 +175,264 @@ func AuthorURLOr(defaultValue string) *u.URL {
 	return authorURLParsed
 }
 
+// branch is the name of the git branch that was used to build the
+// application.
+//
+// Variable name:
+//
+//	xiam.li/meta.branch
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.branch=main'"
+//	-ldflags "-X 'xiam.li/meta.branch=$(git rev-parse --abbrev-ref HEAD)'"
+var branch string
+
+// Branch is the name of the git branch used to build the application.
+func Branch() string {
+	return branch
+}
+
+// BranchOr is the name of the git branch used to build the application, or the given default value if not set.
+func BranchOr(defaultValue string) string {
+	if branch == "" {
+		return defaultValue
+	}
+
+	return branch
+}
+
+// build_host is the hostname of the machine that built the application.
+//
+// Variable name:
+//
+//	xiam.li/meta.build_host
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.build_host=$(hostname)'"
+var build_host string
+
+// BuildHost is the hostname of the machine that built the application.
+func BuildHost() string {
+	return build_host
+}
+
+// BuildHostOr is the hostname of the machine that built the application, or the given default value if not set.
+func BuildHostOr(defaultValue string) string {
+	if build_host == "" {
+		return defaultValue
+	}
+
+	return build_host
+}
+
+// build_id is the identifier of the CI pipeline or job that built the
+// application, like a GitHub Actions run ID or a GitLab pipeline ID.
+//
+// Variable name:
+//
+//	xiam.li/meta.build_id
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.build_id=1234567890'"
+//	-ldflags "-X 'xiam.li/meta.build_id=${GITHUB_RUN_ID}'"
+var build_id string
+
+// BuildID is the identifier of the CI pipeline or job that built the application.
+func BuildID() string {
+	return build_id
+}
+
+// BuildIDOr is the identifier of the CI pipeline or job that built the application, or the given default value if not set.
+func BuildIDOr(defaultValue string) string {
+	if build_id == "" {
+		return defaultValue
+	}
+
+	return build_id
+}
+
+// build_url is a URL for the CI pipeline or job that built the application.
+// Typically links to a page with the build logs.
+//
+// Variable name:
+//
+//	xiam.li/meta.build_url
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.build_url=https://ci.example.com/demo/builds/123'"
+var build_url string
+
+var buildURLParsed = mustURL("xiam.li/meta.build_url", build_url)
+
+// BuildURL is the URL for the CI pipeline or job that built the application.
+func BuildURL() *u.URL {
+	return buildURLParsed
+}
+
+// BuildURLOr is the URL for the CI pipeline or job that built the application, or the given default value if not set.
+func BuildURLOr(defaultValue string) *u.URL {
+	if buildURLParsed == nil {
+		return mustURL("xiam.li/meta.build_url", defaultValue)
+	}
+
+	return buildURLParsed
+}
+
+// build_user is the name of the user that built the application.
+//
+// Variable name:
+//
+//	xiam.li/meta.build_user
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.build_user=$(whoami)'"
+var build_user string
+
+// BuildUser is the name of the user that built the application.
+func BuildUser() string {
+	return build_user
+}
+
+// BuildUserOr is the name of the user that built the application, or the given default value if not set.
+func BuildUserOr(defaultValue string) string {
+	if build_user == "" {
+		return defaultValue
+	}
+
+	return build_user
+}
+
+// ci is the name of the CI system that built the application, like
+// github-actions or gitlab. Not set for builds outside of CI.
+//
+// Variable name:
+//
+//	xiam.li/meta.ci
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.ci=github-actions'"
+var ci string
+
+// CI is the name of the CI system that built the application.
+func CI() string {
+	return ci
+}
+
+// CIOr is the name of the CI system that built the application, or the given default value if not set.
+func CIOr(defaultValue string) string {
+	if ci == "" {
+		return defaultValue
+	}
+
+	return ci
+}
+
+// commit_author is the author of the git commit that was used to build the
+// application. May contain their name, email address, or optionally both.
+//
+// Variable name:
+//
+//	xiam.li/meta.commit_author
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.commit_author=Jane Doe <jdoe@example.com>'"
+//	-ldflags "-X 'xiam.li/meta.commit_author=$(git show -s --format='%an <%ae>')'"
+var commit_author string
+
+var commitAuthorParsed, commitAuthorEmailParsed = mustAuthor("xiam.li/meta.commit_author", commit_author)
+
+// CommitAuthor is the name of the author of the git commit used to build the
+// application.
+func CommitAuthor() string {
+	return commitAuthorParsed
+}
+
+// CommitAuthorOr is the name of the author of the git commit used to build the
+// application, or the given default value if not set.
+func CommitAuthorOr(defaultValue string) string {
+	if commitAuthorParsed == "" {
+		return defaultValue
+	}
+
+	return commitAuthorParsed
+}
+
+// CommitAuthorEmail is the email address for the author of the git commit used
+// to build the application.
+func CommitAuthorEmail() string {
+	return commitAuthorEmailParsed
+}
+
+// CommitAuthorEmailOr is the email address for the author of the git commit
+// used to build the application, or the given default value if not set.
+func CommitAuthorEmailOr(defaultValue string) string {
+	if commitAuthorEmailParsed == "" {
+		return defaultValue
+	}
+
+	return commitAuthorEmailParsed
+}
+
+// commit_date is the time of the git commit that was used to build the
+// application. Unlike date, this is a source timestamp rather than a build
+// timestamp, so it stays the same when rebuilding the same commit. Supports the
+// same formats as date.
+//
+// Variable name:
+//
+//	xiam.li/meta.commit_date
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.commit_date=$(git show -s --format=%cI)'"
+//	-ldflags "-X 'xiam.li/meta.commit_date=$(git show -s --format=%ct)'"
+var commit_date string
+
+var commitDateParsed = mustTime("xiam.li/meta.commit_date", fallback(commit_date, "vcs.time"))
+
+// CommitDate is the time of the git commit used to build the application.
+func CommitDate() *time.Time {
+	return commitDateParsed
+}
+
+// CommitDateOr is the time of the git commit used to build the application, or
+// the given default value if not set.
+func CommitDateOr(defaultValue time.Time) *time.Time {
+	if commitDateParsed == nil {
+		return &defaultValue
+	}
+
+	return commitDateParsed
+}
+
+// CommitDateFormat is the time of the git commit used to build the
+// application, formatted using the given layout.
+func CommitDateFormat(layout string) string {
+	if commitDateParsed == nil {
+		return ""
+	}
+
+	return commitDateParsed.Format(layout)
+}
+
+// CommitDateFormatOr is the time of the git commit used to build the
+// application, formatted using the given layout, or the given default value if
+// not set.
+func CommitDateFormatOr(layout string, defaultValue string) string {
+	if commitDateParsed == nil {
+		return defaultValue
+	}
+
+	return commitDateParsed.Format(layout)
+}
+
 // copyright is the copyright for the application. Typically the name if the
 // author or organization, sometimes prefixed with a year or year range.
 //
@@ -139,7 +462,8 @@ func CopyrightOr(defaultValue string) string {
 }
 
 // date is the time that the application was built. Supports several common
-// formats.
+// formats, including RFC 1123, RFC 3339, and Unix epoch seconds or
+// milliseconds.
 //
 // Variable name:
 //
@@ -153,9 +477,12 @@ func CopyrightOr(defaultValue string) string {
 //	-ldflags "-X 'xiam.li/meta.date=2019-08-23T11:00:00-07:00'"
 //	-ldflags "-X 'xiam.li/meta.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)'"
 //	-ldflags "-X 'xiam.li/meta.date=2019-08-23T18:00:00Z'"
+//	-ldflags "-X 'xiam.li/meta.date=$(date +%s)'"
+//	-ldflags "-X 'xiam.li/meta.date=$(git show -s --format=%ct)'"
+//	-ldflags "-X 'xiam.li/meta.date=$(git show -s --format=%cI)'"
 var date string
 
-var dateParsed = mustTime("xiam.li/meta.date", date)
+var dateParsed = mustTime("xiam.li/meta.date", fallback(date, "vcs.time"))
 
 // Date is the time at which the application was built.
 func Date() *time.Time {
@@ -237,6 +564,28 @@ func Development() bool {
 	return devParsed
 }
 
+// dirty is whether the git working tree had uncommitted changes when the
+// application was built, like the -dirty suffix added by git describe --dirty.
+// A dirty build cannot be reproduced from the commit given by sha alone.
+//
+// Variable name:
+//
+//	xiam.li/meta.dirty
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.dirty=true'"
+//	-ldflags "-X 'xiam.li/meta.dirty=$(test -n "$(git status --porcelain)" && echo true)'"
+var dirty string
+
+var dirtyParsed = mustBool("xiam.li/meta.dirty", fallback(dirty, "vcs.modified"))
+
+// Dirty is whether the git working tree had uncommitted changes when the
+// application was built.
+func Dirty() bool {
+	return dirtyParsed
+}
+
 // docs is a URL for application documentation. Typically links to a page where
 // a user can find technical documentation.
 //
@@ -270,6 +619,52 @@ func Go() string {
 	return runtime.Version()
 }
 
+// extra is arbitrary application-specific metadata, as key/value pairs. The
+// pairs can be given as key=value separated by semicolons, as a JSON object,
+// or as a base64 encoded JSON object (which avoids any quoting issues). JSON
+// values that are not strings are kept as their JSON text.
+//
+// Variable name:
+//
+//	xiam.li/meta.extra
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.extra=env=production;tier=enterprise'"
+//	-ldflags "-X 'xiam.li/meta.extra={\"env\":\"production\",\"tier\":\"enterprise\"}'"
+//	-ldflags "-X 'xiam.li/meta.extra=$(echo '{"env":"production"}' | base64)'"
+var extra string
+
+var extraParsed = mustExtra("xiam.li/meta.extra", extra)
+
+// Extra is the value of the given key in the application-specific metadata,
+// and whether the key is set.
+func Extra(key string) (string, bool) {
+	value, ok := extraParsed[key]
+
+	return value, ok
+}
+
+// ExtraOr is the value of the given key in the application-specific metadata, or the given default value if not set.
+func ExtraOr(key, defaultValue string) string {
+	if value, ok := extraParsed[key]; ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// ExtraAll is all application-specific metadata. The returned map is a copy,
+// and is empty rather than nil if not set.
+func ExtraAll() map[string]string {
+	all := make(map[string]string, len(extraParsed))
+	for key, value := range extraParsed {
+		all[key] = value
+	}
+
+	return all
+}
+
 // license is the license identifier for the application. Should not the full
 // license body, but one of the identifiers from https://spdx.org/licenses, so
 // that the type of license can be easily determined.
@@ -385,8 +780,70 @@ func OS() string {
 	return runtime.GOOS
 }
 
-// sha is the git SHA that was used to build the application. A 40 character
-// "long" SHA should be provided.
+// repo is the URL of the remote git repository that the application was built
+// from. Unlike src, this is meant to be cloned rather than browsed, so SSH
+// URLs are also accepted, including the scp-like syntax used by git. URLs
+// containing a password are rejected, to avoid embedding credentials.
+//
+// Variable name:
+//
+//	xiam.li/meta.repo
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.repo=https://example.com/demo.git'"
+//	-ldflags "-X 'xiam.li/meta.repo=git@example.com:demo.git'"
+//	-ldflags "-X 'xiam.li/meta.repo=$(git remote get-url origin)'"
+var repo string
+
+var repoParsed = mustRepo("xiam.li/meta.repo", repo)
+
+// Repo is the URL of the remote git repository for the application. The
+// scp-like syntax is converted to an ssh:// URL.
+func Repo() *u.URL {
+	return repoParsed
+}
+
+// RepoOr is the URL of the remote git repository for the application, or the given default value if not set.
+func RepoOr(defaultValue string) *u.URL {
+	if repoParsed == nil {
+		return mustRepo("xiam.li/meta.repo", defaultValue)
+	}
+
+	return repoParsed
+}
+
+// reproducible is whether the application was built reproducibly. In a
+// reproducible build, date is not the wall-clock build time, but is derived
+// from the source instead (typically from SOURCE_DATE_EPOCH or the commit
+// time), so that rebuilding the same source produces an identical binary.
+// See https://reproducible-builds.org/docs/source-date-epoch. If date is not
+// set and falls back to the vcs.time build setting, the build is also
+// considered reproducible, as that is the commit time.
+//
+// Variable name:
+//
+//	xiam.li/meta.reproducible
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.reproducible=true'"
+var reproducible string
+
+var reproducibleParsed = mustBool("xiam.li/meta.reproducible", reproducible) ||
+	(date == "" && dateParsed != nil)
+
+// Reproducible is whether the application was built reproducibly, in which
+// case Date is a source timestamp rather than the time of the build.
+func Reproducible() bool {
+	return reproducibleParsed
+}
+
+// sha is the git SHA that was used to build the application. A "long" SHA
+// should be provided, which is 40 characters for the default SHA-1 object
+// format, or 64 characters for the SHA-256 object format. An abbreviated SHA
+// of at least 4 characters is also accepted. Uppercase hex characters are
+// converted to lowercase.
 //
 // Variable name:
 //
@@ -398,7 +855,7 @@ func OS() string {
 //	-ldflags "-X 'xiam.li/meta.sha=$(git rev-parse HEAD)'"
 var sha string
 
-var shaParsed = mustSHA("xiam.li/meta.sha", sha)
+var shaParsed = mustSHA("xiam.li/meta.sha", fallback(sha, "vcs.revision"))
 
 // SHA is the git SHA used to build the application.
 func SHA() string {
@@ -414,18 +871,57 @@ func SHAOr(defaultValue string) string {
 	return shaParsed
 }
 
-// ShortSHA is the git "short" SHA used to build the application.
+// SHAAlgorithm is the hash algorithm of the git SHA used to build the
+// application, either "sha1" or "sha256". Returns an empty string if the SHA
+// is not set or is abbreviated, as the algorithm cannot be determined.
+func SHAAlgorithm() string {
+	return shaAlgorithm(shaParsed)
+}
+
+// short_sha_length is the number of characters in the git "short" SHA.
+// Defaults to 7, like git.
+//
+// Variable name:
+//
+//	xiam.li/meta.short_sha_length
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.short_sha_length=12'"
+var short_sha_length string
+
+var shortSHALengthParsed = mustShortSHALength("xiam.li/meta.short_sha_length", short_sha_length)
+
+// ShortSHA is the git "short" SHA used to build the application. The length
+// is given by xiam.li/meta.short_sha_length. An abbreviated SHA that is
+// already shorter is returned as is.
 func ShortSHA() string {
+	return shortSHA(shaParsed, shortSHALengthParsed)
+}
+
+// ShortSHAOr is the git "short" SHA used to build the application, or the given default value if not set.
+func ShortSHAOr(defaultValue string) string {
 	if shaParsed == "" {
-		return ""
+		return defaultValue
 	}
 
-	return shaParsed[:7]
+	return ShortSHA()
 }
 
-// ShortSHAOr is the git "short" SHA used to build the application, or the given default value if not set.
-func ShortSHAOr(defaultValue string) string {
-	return SHAOr(defaultValue)[:7]
+// Revision is the git "short" SHA used to build the application, with a
+// -dirty suffix if the working tree had uncommitted changes, like
+// bb2fecb-dirty.
+func Revision() string {
+	return revision(ShortSHA(), Dirty())
+}
+
+// revision appends a -dirty suffix to the given short SHA if dirty.
+func revision(shortSHA string, dirty bool) string {
+	if shortSHA == "" || !dirty {
+		return shortSHA
+	}
+
+	return shortSHA + "-dirty"
 }
 
 // src is a URL for the application source code. Typically links to a
@@ -456,6 +952,56 @@ func SourceOr(defaultValue string) *u.URL {
 	return srcParsed
 }
 
+// strict is whether a malformed value for any other variable causes a panic
+// during package initialization. Strict mode is enabled by default. When
+// disabled, malformed values are treated as if they were not set, and are
+// reported by Errors instead.
+//
+// Variable name:
+//
+//	xiam.li/meta.strict
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.strict=false'"
+var strict string
+
+var strictParsed = mustStrict("xiam.li/meta.strict", strict)
+
+// Strict is whether malformed values cause a panic rather than being reported
+// by Errors.
+func Strict() bool {
+	return strictParsed
+}
+
+// tag is the git tag that points at the commit that was used to build the
+// application, if any. Unlike DescribeTag, this is only set if the commit
+// itself is tagged.
+//
+// Variable name:
+//
+//	xiam.li/meta.tag
+//
+// Examples:
+//
+//	-ldflags "-X 'xiam.li/meta.tag=v1.2.3'"
+//	-ldflags "-X 'xiam.li/meta.tag=$(git describe --tags --exact-match)'"
+var tag string
+
+// Tag is the git tag that points at the commit used to build the application.
+func Tag() string {
+	return tag
+}
+
+// TagOr is the git tag that points at the commit used to build the application, or the given default value if not set.
+func TagOr(defaultValue string) string {
+	if tag == "" {
+		return defaultValue
+	}
+
+	return tag
+}
+
 // title is the title of the application. Typically a full or non-abbreviated
 // form of the application name.
 //
@@ -523,26 +1069,65 @@ func URLOr(defaultValue string) *u.URL {
 //	-ldflags "-X 'xiam.li/meta.version=development'"
 //	-ldflags "-X 'xiam.li/meta.version=v1.0.0'"
 //	-ldflags "-X 'xiam.li/meta.version=$(git describe)'"
+//
+// When given the output of git describe for a commit after a tag, like
+// v1.4.2-13-gbb2fecb, the semver parts are taken from the tag, see DescribeTag.
 var version string
 
+var versionParsed = fallbackVersion(version)
+
+// fallbackVersion returns the given ldflags value, or the main module version
+// if not set.
+func fallbackVersion(raw string) string {
+	if raw != "" {
+		return raw
+	}
+
+	return buildVersion
+}
+
 // Version is the version slug for the application.
 func Version() string {
-	return version
+	return versionParsed
 }
 
 // VersionOr is the version slug for the application, or the given default value if not set.
 func VersionOr(defaultValue string) string {
-	if version == "" {
+	if versionParsed == "" {
 		versionMajor, versionMinor, versionPatch, versionPreRelease,
 			versionBuild = mustSemver("xiam.li/version", defaultValue)
 
 		return defaultValue
 	}
 
-	return version
+	return versionParsed
+}
+
+var describeTag, commitsSinceTag, describeSHA = mustDescribe("xiam.li/meta.version", versionParsed, shaParsed)
+
+// DescribeTag is the tag that the version was described from, if the version
+// is the output of git describe. For example, given v1.4.2-13-gbb2fecb, this
+// is v1.4.2. A version without a commit count and abbreviated SHA is assumed
+// to be a tag itself, and is returned as is.
+func DescribeTag() string {
+	return describeTag
+}
+
+// CommitsSinceTag is the number of commits since DescribeTag, if the version
+// is the output of git describe. For example, given v1.4.2-13-gbb2fecb, this
+// is 13.
+func CommitsSinceTag() int {
+	return commitsSinceTag
+}
+
+// DescribeSHA is the abbreviated git SHA, if the version is the output of git
+// describe. For example, given v1.4.2-13-gbb2fecb, this is bb2fecb. If
+// xiam.li/meta.sha is also set, it must start with this abbreviated SHA.
+func DescribeSHA() string {
+	return describeSHA
 }
 
-var versionMajor, versionMinor, versionPatch, versionPreRelease, versionBuild = mustSemver("xiam.li/version", version)
+var versionMajor, versionMinor, versionPatch, versionPreRelease, versionBuild = mustSemver("xiam.li/version", describeTag)
 
 // VersionMajor is the semver major version.
 // See https://semver.org.
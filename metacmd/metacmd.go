@@ -0,0 +1,206 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package metacmd provides a version subcommand, and helpers for describing
+// commands with application metadata. It does not depend on any command line
+// library, but has thin adapters for the common ones. The version subcommand
+// parses its own flags, so the library must not. For github.com/spf13/cobra:
+//
+//	rootCmd := &cobra.Command{Use: "demo-app"}
+//	metacmd.Fill(rootCmd)
+//	rootCmd.AddCommand(&cobra.Command{
+//		Use:                "version",
+//		Short:              "Print version information",
+//		DisableFlagParsing: true,
+//		RunE:               metacmd.RunE[*cobra.Command],
+//	})
+//
+// For github.com/urfave/cli/v2:
+//
+//	app := &cli.App{Name: "demo-app"}
+//	metacmd.Fill(app)
+//	app.Commands = append(app.Commands, &cli.Command{
+//		Name:            "version",
+//		Usage:           "Print version information",
+//		SkipFlagParsing: true,
+//		Action:          metacmd.Action[cli.Args, *cli.Context],
+//	})
+package metacmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"xiam.li/meta"
+)
+
+// Version runs the version subcommand for the application metadata. See
+// VersionFor.
+func Version(args []string, out io.Writer) error {
+	return VersionFor(meta.Get(), args, out)
+}
+
+// VersionFor runs the version subcommand for the given metadata snapshot,
+// with the given arguments (not including the subcommand name), and writes
+// version information to the given writer. The format of the version
+// information depends on the -o (or -output) flag:
+//
+//	version           demo-app v1.2.3 (bb2fecb) built 2019-08-23T18:00:00Z
+//	version -o short  demo-app v1.2.3
+//	version -o full   one "key: value" line per field, see meta.Info.MarshalText
+//	version -o json   a JSON object, see meta.Info
+//
+// These are the same formats as the -version flag, see
+// meta.RegisterVersionFlag.
+func VersionFor(info meta.Info, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	flags.SetOutput(out)
+
+	var format string
+	usage := "output format (short, full, or json)"
+	flags.StringVar(&format, "o", "", usage)
+	flags.StringVar(&format, "output", "", usage)
+
+	if err := flags.Parse(args); err != nil {
+		// The usage was already printed for -h or -help.
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return err
+	}
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	var output string
+
+	switch format {
+	case "":
+		output = info.Banner() + "\n"
+	case "short":
+		// The same as the built-in short template, see meta.Format.
+		var parts []string
+		for _, part := range []string{info.Name, info.Version} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+
+		output = strings.Join(parts, " ") + "\n"
+	case "full":
+		text, err := info.MarshalText()
+		if err != nil {
+			return err
+		}
+
+		output = string(text)
+	case "json":
+		body, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+
+		output = string(body) + "\n"
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	_, err := io.WriteString(out, output)
+
+	return err
+}
+
+// RunE adapts Version to the RunE field of a github.com/spf13/cobra command,
+// as RunE[*cobra.Command]. Version information is written to the command's
+// output. The command must set DisableFlagParsing, so that the -o flag is
+// passed through.
+func RunE[C interface{ OutOrStdout() io.Writer }](cmd C, args []string) error {
+	return Version(args, cmd.OutOrStdout())
+}
+
+// Action adapts Version to the Action field of a github.com/urfave/cli/v2
+// command, as Action[cli.Args, *cli.Context]. Version information is written
+// to the application's writer. The command must set SkipFlagParsing, so that
+// the -o flag is passed through.
+func Action[A interface{ Slice() []string }, C interface{ Args() A }](ctx C) error {
+	return Version(ctx.Args().Slice(), appWriter(ctx))
+}
+
+// appWriter returns the Writer field of the App field of the given
+// github.com/urfave/cli/v2 context, or stdout if it is not set. The fields are
+// read with reflection, as constraints can't require fields.
+func appWriter(ctx interface{}) io.Writer {
+	value := reflect.Indirect(reflect.ValueOf(ctx))
+	if value.Kind() != reflect.Struct {
+		return os.Stdout
+	}
+
+	app := reflect.Indirect(value.FieldByName("App"))
+	if app.Kind() != reflect.Struct {
+		return os.Stdout
+	}
+
+	field := app.FieldByName("Writer")
+	if !field.IsValid() || !field.CanInterface() {
+		return os.Stdout
+	}
+
+	if writer, ok := field.Interface().(io.Writer); ok && writer != nil {
+		return writer
+	}
+
+	return os.Stdout
+}
+
+// Fill describes the given command with the application metadata. See
+// FillFor.
+func Fill(cmd interface{}) {
+	FillFor(cmd, meta.Get())
+}
+
+// FillFor describes the given command with the given metadata snapshot. The
+// command must be a pointer to a struct, like *cobra.Command or *cli.App. The
+// following string fields are set, if the struct has them and they are empty:
+//
+//	Version      the version
+//	Short        the title, or the name if the title is not set (cobra)
+//	Long         the description (cobra)
+//	Usage        the title, or the name if the title is not set (urfave/cli)
+//	Description  the description (urfave/cli)
+//
+// Panics if the command is not a pointer to a struct.
+func FillFor(cmd interface{}, info meta.Info) {
+	value := reflect.ValueOf(cmd)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		panic(errors.New("metacmd: command must be a non-nil pointer to a struct"))
+	}
+
+	short := info.Title
+	if short == "" {
+		short = info.Name
+	}
+
+	fields := map[string]string{
+		"Version":     info.Version,
+		"Short":       short,
+		"Long":        info.Description,
+		"Usage":       short,
+		"Description": info.Description,
+	}
+
+	for name, text := range fields {
+		field := value.Elem().FieldByName(name)
+		if field.IsValid() && field.CanSet() && field.Kind() == reflect.String && field.String() == "" {
+			field.SetString(text)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metacmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"xiam.li/meta"
+)
+
+func TestVersionFor(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	info := meta.Info{
+		Name:     "demo-app",
+		Version:  "v1.2.3",
+		ShortSHA: "bb2fecb",
+		Dirty:    true,
+		Date:     &date,
+	}
+
+	tests := []struct {
+		args     []string
+		expected string
+		err      bool
+	}{
+		{
+			expected: "demo-app v1.2.3 (bb2fecb-dirty) built 2019-08-23T18:00:00Z\n",
+		},
+		{
+			args:     []string{"-o", "short"},
+			expected: "demo-app v1.2.3\n",
+		},
+		{
+			args:     []string{"--output=short"},
+			expected: "demo-app v1.2.3\n",
+		},
+		{
+			args:     []string{"-o", "full"},
+			expected: "name:",
+		},
+		{
+			args:     []string{"-o", "json"},
+			expected: `{"name":"demo-app",`,
+		},
+		{
+			args:     []string{"-h"},
+			expected: "Usage of version:",
+		},
+		{
+			args: []string{"-o", "yaml"},
+			err:  true,
+		},
+		{
+			args: []string{"short"},
+			err:  true,
+		},
+		{
+			args: []string{"--unknown"},
+			err:  true,
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			err := VersionFor(info, test.args, &out)
+
+			switch {
+			case err != nil && !test.err:
+				t.Fatal(err)
+			case err == nil && test.err:
+				t.Fatal("expected an error")
+			case err == nil && !strings.HasPrefix(out.String(), test.expected):
+				t.Fatalf("expected %q to start with %q", out.String(), test.expected)
+			}
+		})
+	}
+}
+
+// command has the same fields and methods as a github.com/spf13/cobra command.
+type command struct {
+	Use     string
+	Short   string
+	Long    string
+	Version string
+	out     io.Writer
+}
+
+func (c *command) OutOrStdout() io.Writer {
+	return c.out
+}
+
+func TestRunE(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := RunE(&command{out: &out}, []string{"-o", "short"}); err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, mustFormat(t, "short"), out.String())
+}
+
+// args and context have the same methods and fields as the
+// github.com/urfave/cli/v2 Args and Context types.
+type (
+	args    []string
+	context struct {
+		App  *struct{ Writer io.Writer }
+		args args
+	}
+)
+
+func (a args) Slice() []string {
+	return a
+}
+
+func (c *context) Args() args {
+	return c.args
+}
+
+func TestAction(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	ctx := &context{App: &struct{ Writer io.Writer }{Writer: &out}, args: args{"-o", "short"}}
+	if err := Action[args, *context](ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, mustFormat(t, "short"), out.String())
+
+	for _, ctx := range []interface{}{&context{}, &context{App: &struct{ Writer io.Writer }{}}, &out} {
+		if appWriter(ctx) != os.Stdout {
+			t.Fatalf("expected stdout for %T", ctx)
+		}
+	}
+}
+
+func TestFillFor(t *testing.T) {
+	t.Parallel()
+
+	cmd := &command{Use: "demo-app", Long: "Custom description"}
+	FillFor(cmd, meta.Info{
+		Name:        "demo-app",
+		Title:       "Demo Application",
+		Description: "A super simple demonstration application",
+		Version:     "v1.2.3",
+	})
+
+	equalString(t, "demo-app", cmd.Use)
+	equalString(t, "Demo Application", cmd.Short)
+	equalString(t, "Custom description", cmd.Long)
+	equalString(t, "v1.2.3", cmd.Version)
+
+	cmd = &command{}
+	FillFor(cmd, meta.Info{Name: "demo-app"})
+
+	equalString(t, "demo-app", cmd.Short)
+	equalString(t, "", cmd.Long)
+	equalString(t, "", cmd.Version)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+
+	FillFor(command{}, meta.Info{})
+}
+
+func TestFillForApp(t *testing.T) {
+	t.Parallel()
+
+	// app has the same fields as a github.com/urfave/cli/v2 App.
+	var app struct {
+		Name        string
+		Usage       string
+		Description string
+		Version     string
+	}
+
+	FillFor(&app, meta.Info{
+		Name:        "demo-app",
+		Title:       "Demo Application",
+		Description: "A super simple demonstration application",
+		Version:     "v1.2.3",
+	})
+
+	equalString(t, "", app.Name)
+	equalString(t, "Demo Application", app.Usage)
+	equalString(t, "A super simple demonstration application", app.Description)
+	equalString(t, "v1.2.3", app.Version)
+}
+
+func mustFormat(t *testing.T, tmpl string) string {
+	t.Helper()
+
+	formatted, err := meta.Format(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return formatted
+}
+
+func equalString(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	if actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+}
@@ -0,0 +1,24 @@
+module xiam.li/meta/metagrpc
+
+go 1.25.0
+
+require (
+	google.golang.org/grpc v1.84.0
+	// No tagged release of xiam.li/meta contains Info.Revision and
+	// ParseDescribe yet, so this is a pseudo-version of a development commit.
+	// Bump it to the first tag that contains them (or to the merged commit)
+	// once it is published.
+	xiam.li/meta v0.0.0-20261017013332-4575167c0cb0
+)
+
+require (
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
+
+// Only used when developing in this repository. Modules that depend on this
+// one ignore it, and use the required version instead.
+replace xiam.li/meta => ..
@@ -0,0 +1,229 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package metagrpc provides gRPC interceptors that exchange application
+// metadata between clients and servers. It is a separate module, so that the
+// gRPC dependency is only required when it is used.
+//
+// Client interceptors attach the application name, version, and revision
+// (the short SHA, see meta.Revision) to the metadata of every outgoing call. Server interceptors record the same
+// values from incoming calls, which are then available to handlers with
+// PeerFromContext, and attach the server's own values to the response
+// headers. A Check function can be set to reject peers, for example those
+// with an incompatible version:
+//
+//	interceptors := metagrpc.New()
+//	interceptors.Check, err = metagrpc.RequireVersion("^1.4")
+//	if err != nil {
+//		// ...
+//	}
+//
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(interceptors.UnaryServer()),
+//		grpc.ChainStreamInterceptor(interceptors.StreamServer()),
+//	)
+//
+// Note that the values are provided by the peer, so they must not be relied
+// on for authentication.
+package metagrpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"xiam.li/meta"
+)
+
+// Metadata keys, which match the headers set by xiam.li/meta/metahttp.
+const (
+	KeyName     = "x-app-name"
+	KeyVersion  = "x-app-version"
+	KeyRevision = "x-app-revision"
+)
+
+// Peer is the application metadata sent by the other side of a call.
+type Peer struct {
+	Name     string
+	Version  string
+	Revision string
+}
+
+// Semver is the parsed semver version of the peer. If the version is the
+// output of git describe, the tag is parsed, like meta.ParsedVersion.
+func (p Peer) Semver() (meta.Semver, error) {
+	tag, _, _ := meta.ParseDescribe(p.Version)
+
+	return meta.ParseSemver(tag)
+}
+
+// PeerFromMetadata returns the application metadata in the given gRPC
+// metadata, like the incoming metadata of a call or the response headers.
+func PeerFromMetadata(md metadata.MD) Peer {
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	}
+
+	return Peer{
+		Name:     first(KeyName),
+		Version:  first(KeyVersion),
+		Revision: first(KeyRevision),
+	}
+}
+
+// peerKey is the context key for the peer.
+type peerKey struct{}
+
+// PeerFromContext returns the peer recorded by the server interceptors.
+// Returns false if the call was not intercepted.
+func PeerFromContext(ctx context.Context) (Peer, bool) {
+	peer, ok := ctx.Value(peerKey{}).(Peer)
+
+	return peer, ok
+}
+
+// RequireVersion returns a Check function that rejects peers whose version
+// does not satisfy the given constraint, see meta.ParseConstraint. Peers that
+// do not send a version are not rejected, but peers that send a version that
+// is not semver are.
+func RequireVersion(constraint string) (func(Peer) error, error) {
+	parsed, err := meta.ParseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(peer Peer) error {
+		if peer.Version == "" {
+			return nil
+		}
+
+		version, err := peer.Semver()
+		if err != nil {
+			return err
+		}
+
+		if !parsed.Check(version) {
+			return fmt.Errorf("version %s does not satisfy %s", peer.Version, constraint)
+		}
+
+		return nil
+	}, nil
+}
+
+// Interceptors exchange application metadata with peers.
+type Interceptors struct {
+	// Info is the metadata sent to peers.
+	Info meta.Info
+
+	// Check is called by the server interceptors for every incoming call.
+	// If it returns an error, the call is rejected with the
+	// FailedPrecondition status code. May be nil.
+	Check func(Peer) error
+}
+
+// New returns interceptors that send the application metadata.
+func New() *Interceptors {
+	return NewFor(meta.Get())
+}
+
+// NewFor returns interceptors that send the given metadata snapshot.
+func NewFor(info meta.Info) *Interceptors {
+	return &Interceptors{Info: info}
+}
+
+// pairs returns the metadata to send to peers. Values that are not set are
+// omitted.
+func (i *Interceptors) pairs() []string {
+	var kv []string
+
+	for _, pair := range [][2]string{
+		{KeyName, i.Info.Name},
+		{KeyVersion, i.Info.Version},
+		{KeyRevision, i.Info.Revision()},
+	} {
+		if pair[1] != "" {
+			kv = append(kv, pair[0], pair[1])
+		}
+	}
+
+	return kv
+}
+
+// UnaryClient returns a client interceptor for unary calls.
+func (i *Interceptors) UnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(metadata.AppendToOutgoingContext(ctx, i.pairs()...), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClient returns a client interceptor for streaming calls.
+func (i *Interceptors) StreamClient() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(metadata.AppendToOutgoingContext(ctx, i.pairs()...), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServer returns a server interceptor for unary calls.
+func (i *Interceptors) UnaryServer() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.accept(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(i.pairs()...)); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServer returns a server interceptor for streaming calls.
+func (i *Interceptors) StreamServer() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.accept(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		if err := ss.SetHeader(metadata.Pairs(i.pairs()...)); err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// accept records the peer from the incoming metadata in the returned context,
+// and checks it.
+func (i *Interceptors) accept(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	peer := PeerFromMetadata(md)
+
+	if i.Check != nil {
+		if err := i.Check(peer); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "incompatible peer: %v", err)
+		}
+	}
+
+	return context.WithValue(ctx, peerKey{}, peer), nil
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context //nolint:containedctx
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
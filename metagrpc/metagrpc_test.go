@@ -0,0 +1,164 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metagrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"xiam.li/meta"
+)
+
+// recorder is a health server that records the peer of every call.
+type recorder struct {
+	*health.Server
+	peers chan Peer
+}
+
+func (r *recorder) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	peer, _ := PeerFromContext(ctx)
+	r.peers <- peer
+
+	return r.Server.Check(ctx, req)
+}
+
+func (r *recorder) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	peer, _ := PeerFromContext(stream.Context())
+	r.peers <- peer
+
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+// serve starts an in-process server with the given interceptors, and returns
+// a client connected to it.
+func serve(t *testing.T, server, client *Interceptors) (healthpb.HealthClient, chan Peer) {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	rec := &recorder{Server: health.NewServer(), peers: make(chan Peer, 2)}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.UnaryServer()),
+		grpc.ChainStreamInterceptor(server.StreamServer()),
+	)
+	healthpb.RegisterHealthServer(srv, rec)
+
+	go srv.Serve(listener) //nolint:errcheck
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(client.UnaryClient()),
+		grpc.WithChainStreamInterceptor(client.StreamClient()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn), rec.peers
+}
+
+func TestInterceptors(t *testing.T) {
+	t.Parallel()
+
+	server := NewFor(meta.Info{Name: "demo-server", Version: "v2.0.0", ShortSHA: "aaaaaaa", Dirty: true})
+	client := NewFor(meta.Info{Name: "demo-client", Version: "v1.2.3", ShortSHA: "bb2fecb"})
+	expected := Peer{Name: "demo-client", Version: "v1.2.3", Revision: "bb2fecb"}
+
+	health, peers := serve(t, server, client)
+
+	var header metadata.MD
+	if _, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+
+	equalPeer(t, expected, <-peers)
+	equalPeer(t, Peer{Name: "demo-server", Version: "v2.0.0", Revision: "aaaaaaa-dirty"}, PeerFromMetadata(header))
+
+	stream, err := health.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	equalPeer(t, expected, <-peers)
+}
+
+func TestRequireVersion(t *testing.T) {
+	t.Parallel()
+
+	check, err := RequireVersion("^1.4.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version  string
+		expected codes.Code
+	}{
+		{version: "v1.4.2", expected: codes.OK},
+		{version: "v1.4.2-13-gbb2fecb", expected: codes.OK},
+		{version: "", expected: codes.OK},
+		{version: "v1.4.1", expected: codes.FailedPrecondition},
+		{version: "v2.0.0", expected: codes.FailedPrecondition},
+		{version: "latest", expected: codes.FailedPrecondition},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.version, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewFor(meta.Info{Name: "demo-server"})
+			server.Check = check
+
+			health, _ := serve(t, server, NewFor(meta.Info{Version: test.version}))
+
+			_, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+			if actual := status.Code(err); actual != test.expected {
+				t.Fatalf("expected %v but got %v", test.expected, actual)
+			}
+
+			stream, err := health.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+
+			if actual := status.Code(err); actual != test.expected {
+				t.Fatalf("expected %v but got %v", test.expected, actual)
+			}
+		})
+	}
+
+	if _, err := RequireVersion("~>1"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func equalPeer(t *testing.T, expected, actual Peer) {
+	t.Helper()
+
+	if actual != expected {
+		t.Fatalf("expected %+v but got %+v", expected, actual)
+	}
+}
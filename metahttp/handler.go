@@ -0,0 +1,334 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package metahttp provides HTTP handlers and middleware for exposing
+// application metadata, for example as a /version endpoint.
+package metahttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"xiam.li/meta"
+)
+
+// Supported response content types.
+const (
+	contentTypeHTML = "text/html"
+	contentTypeJSON = "application/json"
+	contentTypeText = "text/plain"
+)
+
+// formats is a short name for each supported content type.
+var formats = map[string]string{
+	contentTypeHTML: "html",
+	contentTypeJSON: "json",
+	contentTypeText: "text",
+}
+
+// handler serves a metadata snapshot.
+type handler struct {
+	info meta.Info
+}
+
+// Handler returns a handler that serves the application metadata snapshot.
+// See HandlerFor.
+func Handler() http.Handler {
+	return HandlerFor(meta.Get())
+}
+
+// HandlerFor returns a handler that serves the given metadata snapshot.
+//
+// The response is formatted as JSON, plain text, or HTML depending on the
+// request Accept header, defaulting to JSON. The fields query parameter can be
+// used to only include specific fields, as a comma separated list of JSON
+// field names, like ?fields=version,sha. The fields are returned in the
+// requested order. If the snapshot contains a git SHA, it is used for the
+// response ETag.
+func HandlerFor(info meta.Info) http.Handler {
+	return handler{info: info}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	fields, err := filter(h.info, r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	contentType := negotiate(r.Header.Get("Accept"))
+
+	w.Header().Set("Vary", "Accept")
+
+	if etag := etag(h.info, contentType); etag != "" {
+		w.Header().Set("ETag", etag)
+
+		if match(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+	}
+
+	body, err := render(contentType, h.info, fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method != http.MethodHead {
+		w.Write(body) //nolint:errcheck
+	}
+}
+
+// filter returns the names of the fields to include in the response. Returns
+// nil if all fields should be included.
+func filter(info meta.Info, raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool)
+	for _, field := range info.Fields() {
+		known[field.Key] = true
+	}
+
+	var fields []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+
+		fields = append(fields, name)
+	}
+
+	return fields, nil
+}
+
+// negotiate returns the supported content type with the highest quality
+// value in the given Accept header. Defaults to JSON.
+func negotiate(accept string) string {
+	var (
+		best    = contentTypeJSON
+		bestQ   = -1.0
+		offered = []string{contentTypeJSON, contentTypeText, contentTypeHTML}
+	)
+
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "q" {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		for _, candidate := range offered {
+			if q > bestQ && q > 0 && matchMediaType(mediaType, candidate) {
+				best, bestQ = candidate, q
+
+				break
+			}
+		}
+	}
+
+	return best
+}
+
+// matchMediaType is whether the given media range, like "text/*", matches the
+// given content type.
+func matchMediaType(mediaRange, contentType string) bool {
+	switch {
+	case mediaRange == "*/*", mediaRange == contentType:
+		return true
+	case strings.HasSuffix(mediaRange, "/*"):
+		return strings.HasPrefix(contentType, strings.TrimSuffix(mediaRange, "*"))
+	default:
+		return false
+	}
+}
+
+// etag returns the ETag for the given snapshot formatted as the given content
+// type, or an empty string if the snapshot has no SHA. The SHA identifies the
+// source, but builds of the same commit can still differ in their build date
+// and uncommitted changes, so those are included as well. Different content
+// types are different representations, and need their own ETag.
+func etag(info meta.Info, contentType string) string {
+	if info.SHA == "" {
+		return ""
+	}
+
+	parts := []string{info.SHA}
+
+	if info.Dirty {
+		parts = append(parts, "dirty")
+	}
+
+	if info.Date != nil {
+		parts = append(parts, strconv.FormatInt(info.Date.Unix(), 10))
+	}
+
+	parts = append(parts, formats[contentType])
+
+	return `"` + strings.Join(parts, "-") + `"`
+}
+
+// match is whether the given If-None-Match header matches the given ETag.
+func match(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// render formats the given snapshot as the given content type. If fields is
+// not nil, only those fields are included.
+func render(contentType string, info meta.Info, fields []string) ([]byte, error) {
+	switch contentType {
+	case contentTypeHTML:
+		return renderHTML(selected(info, fields))
+	case contentTypeText:
+		return renderText(selected(info, fields)), nil
+	default:
+		return renderJSON(info, fields)
+	}
+}
+
+// selected returns the fields of the given snapshot to include in a text or
+// HTML response. Fields that are not set are omitted, unless explicitly
+// requested.
+func selected(info meta.Info, names []string) []meta.Field {
+	var fields []meta.Field
+
+	if names == nil {
+		for _, field := range info.Fields() {
+			if field.Value != "" {
+				fields = append(fields, field)
+			}
+		}
+
+		return fields
+	}
+
+	values := make(map[string]string)
+	for _, field := range info.Fields() {
+		values[field.Key] = field.Value
+	}
+
+	for _, name := range names {
+		fields = append(fields, meta.Field{Key: name, Value: values[name]})
+	}
+
+	return fields
+}
+
+func renderJSON(info meta.Info, fields []string) ([]byte, error) {
+	body, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields != nil {
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(body, &all); err != nil {
+			return nil, err
+		}
+
+		// Build the object by hand, as a map would be serialized in
+		// alphabetical order rather than in the requested order.
+		var buffer bytes.Buffer
+
+		buffer.WriteString("{")
+
+		for index, name := range fields {
+			if index > 0 {
+				buffer.WriteString(",")
+			}
+
+			key, err := json.Marshal(name)
+			if err != nil {
+				return nil, err
+			}
+
+			buffer.Write(key)
+			buffer.WriteString(":")
+			buffer.Write(all[name])
+		}
+
+		buffer.WriteString("}")
+		body = buffer.Bytes()
+	}
+
+	return append(body, '\n'), nil
+}
+
+func renderText(fields []meta.Field) []byte {
+	// Find the longest key, so that all values can be aligned.
+	var width int
+	for _, field := range fields {
+		if len(field.Key) > width {
+			width = len(field.Key)
+		}
+	}
+
+	var builder strings.Builder
+	for _, field := range fields {
+		line := fmt.Sprintf("%-*s %s", width+1, field.Key+":", field.Value)
+		builder.WriteString(strings.TrimRight(line, " "))
+		builder.WriteString("\n")
+	}
+
+	return []byte(builder.String())
+}
+
+var htmlTemplate = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Version</title></head>
+<body>
+<table>
+{{- range . }}
+<tr><th>{{ .Key }}</th><td>{{ .Value }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+func renderHTML(fields []meta.Field) ([]byte, error) {
+	var builder strings.Builder
+	if err := htmlTemplate.Execute(&builder, fields); err != nil {
+		return nil, err
+	}
+
+	return []byte(builder.String()), nil
+}
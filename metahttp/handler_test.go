@@ -0,0 +1,170 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metahttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"xiam.li/meta"
+)
+
+var testInfo = meta.Info{
+	Name:    "demo-app",
+	Version: "v1.2.3",
+	SHA:     "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+}
+
+func TestHandler(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	tests := []struct {
+		method              string
+		target              string
+		accept              string
+		ifNoneMatch         string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			target:              "/",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+		},
+		{
+			target:              "/?fields=version,sha",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"version":"v1.2.3","sha":"bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6"}` + "\n",
+		},
+		{
+			target:              "/?fields=version,note",
+			accept:              "text/plain",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "version: v1.2.3\nnote:\n",
+		},
+		{
+			target:              "/?fields=name",
+			accept:              "text/html;q=0.9, application/json;q=0.1",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/html; charset=utf-8",
+		},
+		{
+			target:              "/",
+			accept:              "text/*",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			target:         "/?fields=unknown",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			method:         http.MethodPost,
+			target:         "/",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			target:         "/",
+			ifNoneMatch:    `"bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6-json"`,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			target:              "/",
+			accept:              "text/plain",
+			ifNoneMatch:         `"bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6-json"`,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			request := httptest.NewRequest(method, test.target, nil)
+			request.Header.Set("Accept", test.accept)
+			request.Header.Set("If-None-Match", test.ifNoneMatch)
+
+			recorder := httptest.NewRecorder()
+			HandlerFor(testInfo).ServeHTTP(recorder, request)
+
+			if recorder.Code != test.expectedStatus {
+				t.Fatalf("expected status %d but got %d", test.expectedStatus, recorder.Code)
+			}
+
+			if test.expectedContentType != "" {
+				equalString(t, test.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if test.expectedBody != "" {
+				equalString(t, test.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	later := date.Add(time.Hour)
+	seen := make(map[string]bool)
+
+	for _, info := range []meta.Info{
+		testInfo,
+		{SHA: testInfo.SHA, Dirty: true},
+		{SHA: testInfo.SHA, Date: &date},
+		{SHA: testInfo.SHA, Date: &later},
+		{SHA: testInfo.SHA, Date: &date, Dirty: true},
+	} {
+		recorder := httptest.NewRecorder()
+		HandlerFor(info).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		etag := recorder.Header().Get("ETag")
+		if seen[etag] {
+			t.Fatalf("expected a unique ETag but got %s again", etag)
+		}
+
+		seen[etag] = true
+	}
+}
+
+func TestHandlerHTML(t *testing.T) {
+	t.Parallel()
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept", "text/html")
+
+	recorder := httptest.NewRecorder()
+	HandlerFor(meta.Info{Name: "<demo>"}).ServeHTTP(recorder, request)
+
+	if !strings.Contains(recorder.Body.String(), "<tr><th>name</th><td>&lt;demo&gt;</td></tr>") {
+		t.Fatalf("expected escaped name in %q", recorder.Body.String())
+	}
+
+	equalString(t, "", recorder.Header().Get("ETag"))
+}
+
+func equalString(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	if actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+}
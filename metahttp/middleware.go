@@ -0,0 +1,32 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metahttp
+
+import (
+	"net/http"
+
+	"xiam.li/meta"
+)
+
+// Middleware wraps the given handler, and adds X-App-Version and
+// X-App-Revision headers to every response, containing the application
+// version and git "short" SHA (see meta.Revision). Headers are omitted if the
+// value is not set.
+func Middleware(next http.Handler) http.Handler {
+	version := meta.Version()
+	revision := meta.Revision()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if version != "" {
+			w.Header().Set("X-App-Version", version)
+		}
+
+		if revision != "" {
+			w.Header().Set("X-App-Revision", revision)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,31 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metahttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"xiam.li/meta"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	recorder := httptest.NewRecorder()
+	Middleware(next).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if recorder.Code != http.StatusTeapot {
+		t.Fatalf("expected status %d but got %d", http.StatusTeapot, recorder.Code)
+	}
+
+	equalString(t, meta.Version(), recorder.Header().Get("X-App-Version"))
+	equalString(t, meta.Revision(), recorder.Header().Get("X-App-Revision"))
+}
@@ -0,0 +1,50 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metahttp
+
+import (
+	"net/http"
+
+	"xiam.li/meta"
+)
+
+// Transport wraps the given round tripper, and sets the User-Agent header of
+// every request to the application user agent (see meta.UserAgent), unless
+// the request already has a User-Agent header. If next is nil,
+// http.DefaultTransport is used.
+//
+//	client := &http.Client{Transport: metahttp.Transport(nil)}
+func Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return transport{next: next, userAgent: meta.UserAgent()}
+}
+
+// transport sets the User-Agent header of outgoing requests.
+type transport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// An explicitly empty User-Agent header is also kept, as it is how a
+	// request opts out of sending one.
+	if _, ok := r.Header["User-Agent"]; ok || t.userAgent == "" {
+		return t.next.RoundTrip(r)
+	}
+
+	// A round tripper must not modify the given request.
+	r = r.Clone(r.Context())
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	r.Header.Set("User-Agent", t.userAgent)
+
+	return t.next.RoundTrip(r)
+}
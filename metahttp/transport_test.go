@@ -0,0 +1,99 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metahttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"xiam.li/meta"
+)
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.UserAgent())) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+
+	tests := []struct {
+		header   http.Header
+		expected string
+	}{
+		{
+			header:   http.Header{},
+			expected: meta.UserAgent(),
+		},
+		{
+			header:   http.Header{"User-Agent": {"curl/8.0.1"}},
+			expected: "curl/8.0.1",
+		},
+		{
+			header:   http.Header{"User-Agent": {""}},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		request.Header = test.header.Clone()
+
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		equalString(t, test.expected, string(body))
+		equalString(t, test.header.Get("User-Agent"), request.Header.Get("User-Agent"))
+	}
+}
+
+func TestTransportNilHeader(t *testing.T) {
+	t.Parallel()
+
+	var actual string
+
+	next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		actual = r.Header.Get("User-Agent")
+
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	})
+
+	request, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request.Header = nil
+
+	if _, err := Transport(next).RoundTrip(request); err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, meta.UserAgent(), actual)
+}
+
+// roundTripperFunc adapts a function to the http.RoundTripper interface.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
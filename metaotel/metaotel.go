@@ -0,0 +1,98 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package metaotel maps application metadata to OpenTelemetry resource
+// attributes, following the semantic conventions
+// (https://opentelemetry.io/docs/specs/semconv/resource/), so that traces,
+// metrics, and logs carry build provenance.
+//
+// Attributes are returned as a plain key/value slice, so that no
+// OpenTelemetry dependency is required. They can be converted with the
+// OpenTelemetry Go API:
+//
+//	var attrs []attribute.KeyValue
+//	for _, attr := range metaotel.Attributes() {
+//		attrs = append(attrs, attribute.String(attr.Key, attr.Value))
+//	}
+//	res := resource.NewSchemaless(attrs...)
+//
+// The xiam.li/meta/metaotel/otelresource module does exactly that.
+//
+// The attributes can also be given to any OpenTelemetry SDK through the
+// OTEL_RESOURCE_ATTRIBUTES environment variable, see EnvironmentValue.
+package metaotel
+
+import (
+	"net/url"
+	"strings"
+
+	"xiam.li/meta"
+)
+
+// Attribute keys, from the OpenTelemetry semantic conventions.
+const (
+	KeyServiceName           = "service.name"
+	KeyServiceVersion        = "service.version"
+	KeyDeploymentEnvironment = "deployment.environment"
+	KeyRevision              = "vcs.repository.ref.revision"
+	KeyRefName               = "vcs.repository.ref.name"
+	KeyRepositoryURL         = "vcs.repository.url.full"
+)
+
+// Attribute is a single resource attribute.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Attributes returns the resource attributes for the application.
+func Attributes() []Attribute {
+	return AttributesFor(meta.Get())
+}
+
+// AttributesFor returns the resource attributes for the given metadata
+// snapshot, in a stable order. Attributes that are not set are omitted. The
+// deployment.environment attribute is only set to "development" for
+// applications in development mode, as the environment is otherwise unknown.
+func AttributesFor(info meta.Info) []Attribute {
+	var environment string
+	if info.Development {
+		environment = "development"
+	}
+
+	repositoryURL := info.Repo
+	if repositoryURL == "" {
+		repositoryURL = info.Source
+	}
+
+	candidates := []Attribute{
+		{KeyServiceName, info.Name},
+		{KeyServiceVersion, info.Version},
+		{KeyDeploymentEnvironment, environment},
+		{KeyRevision, info.SHA},
+		{KeyRefName, info.Branch},
+		{KeyRepositoryURL, repositoryURL},
+	}
+
+	attrs := make([]Attribute, 0, len(candidates))
+	for _, attr := range candidates {
+		if attr.Value != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	return attrs
+}
+
+// EnvironmentValue returns the given attributes in the format of the
+// OTEL_RESOURCE_ATTRIBUTES environment variable, like
+// service.name=demo-app,service.version=v1.2.3. Values are percent-encoded.
+func EnvironmentValue(attrs []Attribute) string {
+	pairs := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		pairs = append(pairs, attr.Key+"="+url.PathEscape(attr.Value))
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,74 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metaotel
+
+import (
+	"fmt"
+	"testing"
+
+	"xiam.li/meta"
+)
+
+func TestAttributesFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		info     meta.Info
+		expected string
+	}{
+		{
+			info:     meta.Info{},
+			expected: "",
+		},
+		{
+			info: meta.Info{
+				Name:        "demo-app",
+				Version:     "v1.2.3",
+				SHA:         "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+				Development: true,
+				Branch:      "main",
+				Source:      "https://example.com/demo",
+				Repo:        "https://example.com/demo.git",
+			},
+			expected: "service.name=demo-app," +
+				"service.version=v1.2.3," +
+				"deployment.environment=development," +
+				"vcs.repository.ref.revision=bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6," +
+				"vcs.repository.ref.name=main," +
+				"vcs.repository.url.full=https:%2F%2Fexample.com%2Fdemo.git",
+		},
+		{
+			info: meta.Info{
+				Name:   "demo app",
+				Source: "https://example.com/demo",
+			},
+			expected: "service.name=demo%20app," +
+				"vcs.repository.url.full=https:%2F%2Fexample.com%2Fdemo",
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			actual := EnvironmentValue(AttributesFor(test.info))
+			if actual != test.expected {
+				t.Fatalf("expected %q but got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	t.Parallel()
+
+	for _, attr := range Attributes() {
+		if attr.Key == KeyServiceVersion && attr.Value != meta.Version() {
+			t.Fatalf("expected %q but got %q", meta.Version(), attr.Value)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+module xiam.li/meta/metaotel/otelresource
+
+go 1.25.0
+
+require (
+	go.opentelemetry.io/otel v1.46.0
+	go.opentelemetry.io/otel/sdk v1.46.0
+	// No tagged release of xiam.li/meta contains metaotel yet, so this is a
+	// pseudo-version of a development commit. Bump it to the first tag that
+	// contains metaotel (or to the merged commit) once it is published.
+	xiam.li/meta v0.0.0-20261017010221-c6b8ca903a66
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel/metric v1.46.0 // indirect
+	go.opentelemetry.io/otel/trace v1.46.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+)
+
+// Only used when developing in this repository. Modules that depend on this
+// one ignore it, and use the required version instead.
+replace xiam.li/meta => ../..
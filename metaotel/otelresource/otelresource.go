@@ -0,0 +1,49 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package otelresource converts application metadata to an OpenTelemetry
+// resource, see xiam.li/meta/metaotel. It is a separate module, so that the
+// OpenTelemetry dependencies are only required when it is used:
+//
+//	res, err := resource.Merge(resource.Default(), otelresource.New())
+//	if err != nil {
+//		// ...
+//	}
+//	provider := sdktrace.NewTracerProvider(sdktrace.WithResource(res))
+package otelresource
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"xiam.li/meta"
+	"xiam.li/meta/metaotel"
+)
+
+// New returns a resource with the attributes for the application.
+func New() *resource.Resource {
+	return NewFor(meta.Get())
+}
+
+// NewFor returns a resource with the attributes for the given metadata
+// snapshot.
+func NewFor(info meta.Info) *resource.Resource {
+	return resource.NewSchemaless(AttributesFor(info)...)
+}
+
+// Attributes returns the attributes for the application.
+func Attributes() []attribute.KeyValue {
+	return AttributesFor(meta.Get())
+}
+
+// AttributesFor returns the attributes for the given metadata snapshot.
+func AttributesFor(info meta.Info) []attribute.KeyValue {
+	attrs := metaotel.AttributesFor(info)
+
+	converted := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		converted = append(converted, attribute.String(attr.Key, attr.Value))
+	}
+
+	return converted
+}
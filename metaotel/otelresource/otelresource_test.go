@@ -0,0 +1,39 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package otelresource
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"xiam.li/meta"
+)
+
+func TestNewFor(t *testing.T) {
+	t.Parallel()
+
+	res := NewFor(meta.Info{
+		Name:    "demo-app",
+		Version: "v1.2.3",
+		SHA:     "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+	})
+
+	tests := map[attribute.Key]string{
+		"service.name":                "demo-app",
+		"service.version":             "v1.2.3",
+		"vcs.repository.ref.revision": "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+	}
+
+	for key, expected := range tests {
+		actual, ok := res.Set().Value(key)
+		if !ok || actual.AsString() != expected {
+			t.Fatalf("expected %s=%q but got %q", key, expected, actual.Emit())
+		}
+	}
+
+	if res.Len() != len(tests) {
+		t.Fatalf("expected %d attributes but got %d", len(tests), res.Len())
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package metaprom exposes application metadata as a Prometheus build_info
+// metric, a gauge with a constant value of 1 that is labeled with the version,
+// revision, and other build information:
+//
+//	# HELP demo_app_build_info A metric with a constant '1' value labeled by version, revision, and other build information.
+//	# TYPE demo_app_build_info gauge
+//	demo_app_build_info{goarch="amd64",goos="linux",goversion="go1.18",revision="bb2fecb...",version="v1.2.3"} 1
+//
+// The metric is rendered in the Prometheus text exposition format, so that
+// no Prometheus client library is required. It can be served directly using
+// Handler, or registered with an existing client library. For
+// github.com/prometheus/client_golang:
+//
+//	collector := metaprom.NewCollector()
+//	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+//		Name:        collector.Name(),
+//		Help:        metaprom.Help,
+//		ConstLabels: collector.Labels(),
+//	})
+//	gauge.Set(1)
+//	prometheus.MustRegister(gauge)
+//
+// For github.com/VictoriaMetrics/metrics:
+//
+//	metrics.RegisterMetricsWriter(metaprom.NewCollector().WritePrometheus)
+package metaprom
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"xiam.li/meta"
+)
+
+// Help is the help text for the build_info metric.
+const Help = "A metric with a constant '1' value labeled by version, revision, and other build information."
+
+// contentType is the content type of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Collector renders a build_info metric.
+type Collector struct {
+	name   string
+	labels map[string]string
+}
+
+// NewCollector returns a collector for the application metadata. The metric
+// is named after the application, like demo_app_build_info, or build_info if
+// the application name is not set.
+func NewCollector() *Collector {
+	return NewCollectorFor(meta.Get())
+}
+
+// NewCollectorFor returns a collector for the given metadata snapshot.
+func NewCollectorFor(info meta.Info) *Collector {
+	name := "build_info"
+	if info.Name != "" {
+		name = sanitize(info.Name) + "_" + name
+	}
+
+	var date string
+	if info.Date != nil {
+		date = info.Date.Format(time.RFC3339)
+	}
+
+	labels := map[string]string{
+		"date":        date,
+		"development": strconv.FormatBool(info.Development),
+		"goarch":      info.Arch,
+		"goos":        info.OS,
+		"goversion":   info.Go,
+		"revision":    info.SHA,
+		"version":     info.Version,
+	}
+
+	// Empty label values are equivalent to missing labels in Prometheus.
+	for key, value := range labels {
+		if value == "" {
+			delete(labels, key)
+		}
+	}
+
+	return &Collector{
+		name:   name,
+		labels: labels,
+	}
+}
+
+// Handler returns a handler that serves the build_info metric for the
+// application metadata.
+func Handler() http.Handler {
+	return NewCollector()
+}
+
+// Name is the name of the metric.
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Labels is the set of labels for the metric.
+func (c *Collector) Labels() map[string]string {
+	labels := make(map[string]string, len(c.labels))
+	for key, value := range c.labels {
+		labels[key] = value
+	}
+
+	return labels
+}
+
+// WritePrometheus writes the metric to the given writer in the Prometheus
+// text exposition format.
+func (c *Collector) WritePrometheus(w io.Writer) {
+	keys := make([]string, 0, len(c.labels))
+	for key := range c.labels {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+`="`+escape(c.labels[key])+`"`)
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n", c.name, Help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", c.name)
+	fmt.Fprintf(w, "%s{%s} 1\n", c.name, strings.Join(pairs, ","))
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var buffer bytes.Buffer
+	c.WritePrometheus(&buffer)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buffer.Bytes()) //nolint:errcheck
+}
+
+// sanitize converts the given value into a valid metric name, by replacing
+// all invalid characters with underscores.
+// See https://prometheus.io/docs/concepts/data_model/#metric-names-and-labels.
+func sanitize(raw string) string {
+	var builder strings.Builder
+
+	for i, r := range raw {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r == '_', r == ':':
+			builder.WriteRune(r)
+		case '0' <= r && r <= '9':
+			// Metric names cannot start with a digit.
+			if i == 0 {
+				builder.WriteRune('_')
+			}
+
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+
+	return builder.String()
+}
+
+// escape escapes the given label value.
+// See https://prometheus.io/docs/instrumenting/exposition_formats/#text-format-details.
+func escape(raw string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(raw)
+}
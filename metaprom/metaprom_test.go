@@ -0,0 +1,84 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package metaprom
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"xiam.li/meta"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	collector := NewCollectorFor(meta.Info{
+		Name:    "demo-app",
+		Date:    &date,
+		Version: `v1.2.3"\`,
+		SHA:     "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+		Go:      "go1.18",
+		OS:      "linux",
+		Arch:    "amd64",
+	})
+
+	equalString(t, "demo_app_build_info", collector.Name())
+
+	var buffer bytes.Buffer
+	collector.WritePrometheus(&buffer)
+
+	expected := "" +
+		"# HELP demo_app_build_info " + Help + "\n" +
+		"# TYPE demo_app_build_info gauge\n" +
+		`demo_app_build_info{date="2019-08-23T18:00:00Z",development="false",goarch="amd64",goos="linux",` +
+		`goversion="go1.18",revision="bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",version="v1.2.3\"\\"} 1` + "\n"
+
+	equalString(t, expected, buffer.String())
+}
+
+func TestCollectorUnnamed(t *testing.T) {
+	t.Parallel()
+
+	collector := NewCollectorFor(meta.Info{})
+
+	equalString(t, "build_info", collector.Name())
+
+	if len(collector.Labels()) != 1 {
+		t.Fatalf("expected only the development label but got %v", collector.Labels())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	equalString(t, contentType, recorder.Header().Get("Content-Type"))
+
+	var buffer bytes.Buffer
+	NewCollector().WritePrometheus(&buffer)
+	equalString(t, buffer.String(), recorder.Body.String())
+}
+
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+
+	equalString(t, "demo_app", sanitize("demo-app"))
+	equalString(t, "_9lives", sanitize("9lives"))
+	equalString(t, "app_v2:x", sanitize("app.v2:x"))
+}
+
+func equalString(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	if actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+}
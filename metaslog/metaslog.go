@@ -0,0 +1,91 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+//go:build go1.21
+
+// Package metaslog adds application metadata to log/slog records, so that
+// structured logs can be correlated to a build. Requires Go 1.21 or later.
+//
+// Wrap a handler with NewHandler to add the service.name, service.version,
+// and vcs.revision attributes to every record:
+//
+//	logger := slog.New(metaslog.NewHandler(slog.NewJSONHandler(os.Stderr, nil)))
+//	logger.Info("starting")
+//	// {"time":"...","level":"INFO","msg":"starting","service.name":"demo-app","service.version":"v1.2.3","vcs.revision":"bb2fecb..."}
+//
+// Use Attr to log the full metadata snapshot once, for example at startup:
+//
+//	slog.Info("starting", metaslog.Attr())
+package metaslog
+
+import (
+	"log/slog"
+
+	"xiam.li/meta"
+)
+
+// Attribute keys added by NewHandler, following the OpenTelemetry semantic
+// conventions where one exists.
+const (
+	KeyServiceName    = "service.name"
+	KeyServiceVersion = "service.version"
+	KeyRevision       = "vcs.revision"
+)
+
+// Attr returns the application metadata snapshot as a group attribute named
+// build. See meta.Info.LogValue.
+func Attr() slog.Attr {
+	return AttrFor(meta.Get())
+}
+
+// AttrFor returns the given metadata snapshot as a group attribute named
+// build.
+func AttrFor(info meta.Info) slog.Attr {
+	return slog.Any("build", info)
+}
+
+// Attrs returns the service.name, service.version, and vcs.revision
+// attributes for the application. Attributes that are not set are omitted.
+func Attrs() []slog.Attr {
+	return AttrsFor(meta.Get())
+}
+
+// AttrsFor returns the service.name, service.version, and vcs.revision
+// attributes for the given metadata snapshot. Attributes that are not set are
+// omitted.
+func AttrsFor(info meta.Info) []slog.Attr {
+	var attrs []slog.Attr
+
+	for _, attr := range []slog.Attr{
+		slog.String(KeyServiceName, info.Name),
+		slog.String(KeyServiceVersion, info.Version),
+		slog.String(KeyRevision, info.SHA),
+	} {
+		if attr.Value.String() != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	return attrs
+}
+
+// NewHandler wraps the given handler, and adds the service.name,
+// service.version, and vcs.revision attributes for the application to every
+// record. The attributes are added at the top level, even for handlers
+// derived with WithGroup.
+func NewHandler(next slog.Handler) slog.Handler {
+	return NewHandlerFor(next, meta.Get())
+}
+
+// NewHandlerFor wraps the given handler, and adds the service.name,
+// service.version, and vcs.revision attributes for the given metadata
+// snapshot to every record.
+func NewHandlerFor(next slog.Handler, info meta.Info) slog.Handler {
+	attrs := AttrsFor(info)
+	if len(attrs) == 0 {
+		return next
+	}
+
+	return next.WithAttrs(attrs)
+}
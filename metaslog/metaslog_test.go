@@ -0,0 +1,84 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+//go:build go1.21
+
+package metaslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"xiam.li/meta"
+)
+
+func TestNewHandlerFor(t *testing.T) {
+	t.Parallel()
+
+	info := meta.Info{
+		Name:    "demo-app",
+		Version: "v1.2.3",
+		SHA:     "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6",
+	}
+
+	var buffer bytes.Buffer
+
+	logger := slog.New(NewHandlerFor(slog.NewJSONHandler(&buffer, nil), info))
+	logger.WithGroup("request").Info("handled", "path", "/")
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, "demo-app", actual["service.name"].(string))
+	equalString(t, "v1.2.3", actual["service.version"].(string))
+	equalString(t, "bb2fecbb4a287ea4c1f9887ca86dd0eb7ff28ec6", actual["vcs.revision"].(string))
+	equalString(t, "/", actual["request"].(map[string]interface{})["path"].(string))
+}
+
+func TestAttrsFor(t *testing.T) {
+	t.Parallel()
+
+	attrs := AttrsFor(meta.Info{Name: "demo-app"})
+	if len(attrs) != 1 {
+		t.Fatalf("expected 1 attribute but got %v", attrs)
+	}
+
+	equalString(t, "service.name=demo-app", attrs[0].String())
+
+	// Without any attributes, the handler is not wrapped.
+	handler := slog.NewTextHandler(&bytes.Buffer{}, nil)
+	if NewHandlerFor(handler, meta.Info{}) != slog.Handler(handler) {
+		t.Fatal("expected the handler to be returned as is")
+	}
+}
+
+func TestAttrFor(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	logger.Info("starting", AttrFor(meta.Info{Name: "demo-app", Version: "v1.2.3"}))
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	build := actual["build"].(map[string]interface{})
+	equalString(t, "demo-app", build["name"].(string))
+	equalString(t, "v1.2.3", build["version"].(string))
+}
+
+func equalString(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	if actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+}
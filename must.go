@@ -5,10 +5,15 @@
 package meta
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/mail"
 	u "net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -63,39 +68,195 @@ func mustSemver(_, raw string) (string, string, string, string, string) {
 
 // mustSHA validates that the given value is a properly formatted git SHA.
 func mustSHA(path, raw string) string {
-	if raw == "" {
+	parsed, err := parseSHA(raw)
+	if err != nil {
+		malformed(path, raw, err)
+
 		return ""
 	}
 
-	// Git SHAs are 40 characters long.
-	const gitSHALength = 40
-	if len(raw) != gitSHALength {
-		panic(fmt.Errorf("malformed ldflags value for %s", path))
+	return parsed
+}
+
+// Lengths of git SHAs, for the SHA-1 and SHA-256 object formats, and the
+// minimum length of an abbreviated SHA.
+const (
+	sha1Length            = 40
+	sha256Length          = 64
+	minSHALength          = 4
+	defaultShortSHALength = 7
+)
+
+// parseSHA parses the given value as a git SHA, which may be abbreviated.
+// Uppercase hex characters are converted to lowercase.
+func parseSHA(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	// Full git SHAs are either 40 (SHA-1) or 64 (SHA-256) characters long,
+	// and abbreviated SHAs are shorter than a full SHA-1.
+	if len(raw) < minSHALength || (sha1Length < len(raw) && len(raw) != sha256Length) {
+		return "", fmt.Errorf("must be %d or %d characters long, or abbreviated to at least %d characters",
+			sha1Length, sha256Length, minSHALength)
 	}
 
-	// Git SHAs are made of only lowercase hex characters.
+	// Git SHAs are made of only hex characters.
 	for _, rune := range raw {
 		switch {
 		case '0' <= rune && rune <= '9':
 		case 'a' <= rune && rune <= 'f':
+		case 'A' <= rune && rune <= 'F':
 		default:
-			panic(fmt.Errorf("malformed ldflags value for %s", path))
+			return "", errors.New("must only contain hex characters")
 		}
 	}
 
-	return raw
+	return strings.ToLower(raw), nil
+}
+
+// shaAlgorithm returns the hash algorithm of the given git SHA, or an empty
+// string if it is abbreviated.
+func shaAlgorithm(sha string) string {
+	switch len(sha) {
+	case sha1Length:
+		return "sha1"
+	case sha256Length:
+		return "sha256"
+	default:
+		return ""
+	}
+}
+
+// mustShortSHALength validates that the given value is a positive number of
+// characters. Defaults to 7.
+func mustShortSHALength(path, raw string) int {
+	if raw == "" {
+		return defaultShortSHALength
+	}
+
+	length, err := parseShortSHALength(raw)
+	if err != nil {
+		malformed(path, raw, err)
+
+		return defaultShortSHALength
+	}
+
+	return length
+}
+
+// parseShortSHALength parses the given value as a short SHA length.
+func parseShortSHALength(raw string) (int, error) {
+	length, err := strconv.Atoi(raw)
+	if err != nil || length < minSHALength || length > sha256Length {
+		return 0, fmt.Errorf("must be a number from %d to %d", minSHALength, sha256Length)
+	}
+
+	return length, nil
+}
+
+// shortSHA returns the first length characters of the given git SHA.
+func shortSHA(sha string, length int) string {
+	if len(sha) <= length {
+		return sha
+	}
+
+	return sha[:length]
+}
+
+// describeRegex matches the output of git describe, like v1.4.2-13-gbb2fecb,
+// which is a tag followed by the number of commits since that tag and the
+// abbreviated SHA of the commit. A -dirty suffix from git describe --dirty is
+// also matched.
+var describeRegex = regexp.MustCompile(`^(.+?)(?:-(\d+)-g([0-9a-f]{4,64}))?(?:-dirty)?$`)
+
+// parseDescribe parses the given git describe output into the base tag, the
+// number of commits since that tag, and the abbreviated SHA. A value without
+// a commit count and abbreviated SHA is a tag itself, as git describe prints
+// only the tag when the described commit is tagged.
+func parseDescribe(raw string) (string, int, string) {
+	matches := describeRegex.FindStringSubmatch(raw)
+	if matches == nil {
+		return raw, 0, ""
+	}
+
+	commits, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return matches[1], 0, ""
+	}
+
+	return matches[1], commits, matches[3]
+}
+
+// mustDescribe parses the given git describe output, and validates that the
+// abbreviated SHA, if any, matches the given full SHA, if any.
+func mustDescribe(path, raw, sha string) (string, int, string) {
+	tag, commits, abbrev := parseDescribe(raw)
+	if err := checkDescribe(abbrev, sha); err != nil {
+		malformed(path, raw, err)
+	}
+
+	return tag, commits, abbrev
+}
+
+// checkDescribe reports if the given abbreviated SHA from git describe output
+// does not match the given full SHA.
+func checkDescribe(abbrev, sha string) error {
+	// Either may be abbreviated further than the other.
+	if abbrev == "" || sha == "" || strings.HasPrefix(sha, abbrev) || strings.HasPrefix(abbrev, sha) {
+		return nil
+	}
+
+	return fmt.Errorf("abbreviated SHA %s does not match xiam.li/meta.sha %s", abbrev, sha)
 }
 
 // mustTime validates that the given value is a properly formatted timestamp.
 // All timestamps are converted to UTC.
 func mustTime(path, raw string) *time.Time {
-	if raw == "" {
+	parsed, err := parseTime(raw)
+	if err != nil {
+		malformed(path, raw, err)
+
 		return nil
 	}
 
+	return parsed
+}
+
+// parseTime parses the given value as a timestamp. All timestamps are
+// converted to UTC. Supported formats are:
+//
+//	Fri, 23 Aug 2019 11:00:00 -0700      RFC 1123 with numeric zone, `date -R`
+//	Fri, 23 Aug 2019 11:00:00 PDT        RFC 1123 with RFC 822 zone name
+//	Fri, 3 Aug 2019 11:00:00 -0700       RFC 2822, `git log --date=rfc`
+//	Fri Aug 23 11:00:00 2019 -0700       `git log --date=default`
+//	2019-08-23T11:00:00-07:00            RFC 3339, `date -Iseconds`, `git log --format=%cI`
+//	2019-08-23T18:00:00.123456789Z       RFC 3339 with fractional seconds
+//	2019-08-23T11:00:00-0700             `date --iso-8601=seconds` on some platforms
+//	1566583200                           Unix epoch seconds, `date +%s`, `git log --format=%ct`
+//	1566583200000                        Unix epoch milliseconds
+//
+// Unix epoch values with 12 or more digits are treated as milliseconds. Zone
+// names are limited to those defined by RFC 822 (UT, GMT, EST, EDT, CST, CDT,
+// MST, MDT, PST, and PDT), as other names are ambiguous.
+func parseTime(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	if t, ok := parseEpoch(raw); ok {
+		return &t, nil
+	}
+
 	layouts := []string{
 		time.RFC1123Z,
 		time.RFC3339,
+		time.RFC3339Nano,
+		// The format produced by `git log --date=rfc`, which doesn't pad
+		// the day of the month.
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		// The format produced by `git log --date=default`.
+		"Mon Jan 2 15:04:05 2006 -0700",
 		// The format sometimes produced by `date --iso-8601=seconds`.
 		// See https://github.com/golang/go/issues/31113#issuecomment-482158617.
 		"2006-01-02T15:04:05Z0700",
@@ -103,37 +264,409 @@ func mustTime(path, raw string) *time.Time {
 
 	// Try each layout until one parses.
 	for _, spec := range layouts {
-		if t, err := time.Parse(spec, raw); err == nil {
+		if t, err := time.Parse(spec, replaceZoneName(raw)); err == nil {
 			t = t.UTC()
 
-			return &t
+			return &t, nil
 		}
 	}
 
-	panic(fmt.Errorf("malformed ldflags value for %s", path))
+	return nil, errors.New("unsupported timestamp format")
+}
+
+// parseEpoch parses the given value as a Unix epoch timestamp, in either
+// seconds or milliseconds.
+func parseEpoch(raw string) (time.Time, bool) {
+	for _, r := range raw {
+		if r < '0' || r > '9' {
+			return time.Time{}, false
+		}
+	}
+
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// Epoch seconds won't have 12 digits until the year 5138, while epoch
+	// milliseconds have had at least 12 digits since 1973.
+	const millisecondDigits = 12
+	if len(raw) >= millisecondDigits {
+		return time.UnixMilli(n).UTC(), true
+	}
+
+	return time.Unix(n, 0).UTC(), true
+}
+
+// rfc822Zones are the zone names defined by RFC 822, and their offsets.
+// See https://www.rfc-editor.org/rfc/rfc822#section-5.1.
+var rfc822Zones = map[string]string{
+	"UT":  "+0000",
+	"GMT": "+0000",
+	"UTC": "+0000",
+	"EST": "-0500",
+	"EDT": "-0400",
+	"CST": "-0600",
+	"CDT": "-0500",
+	"MST": "-0700",
+	"MDT": "-0600",
+	"PST": "-0800",
+	"PDT": "-0700",
+}
+
+// replaceZoneName replaces a trailing RFC 822 zone name in the given value
+// with its numeric offset. The time package would otherwise interpret zone
+// names depending on the local time zone.
+func replaceZoneName(raw string) string {
+	index := strings.LastIndex(raw, " ")
+	if index < 0 {
+		return raw
+	}
+
+	if offset, ok := rfc822Zones[raw[index+1:]]; ok {
+		return raw[:index+1] + offset
+	}
+
+	return raw
 }
 
 // mustURL validates that the given value is a properly formatted URL.
 func mustURL(path, raw string) *u.URL {
-	if raw == "" {
+	parsed, err := parseURL(raw)
+	if err != nil {
+		malformed(path, raw, err)
+
 		return nil
 	}
 
+	return parsed
+}
+
+// parseURL parses the given value as a URL.
+func parseURL(raw string) (*u.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
 	// Parse the URL.
 	parsed, err := u.Parse(raw)
 	if err != nil {
-		panic(fmt.Errorf("malformed ldflags value for %s", path))
+		return nil, err
 	}
 
 	// Require that the scheme is http:// or https://.
 	if parsed.Scheme != "http" && parsed.Scheme != "https" {
-		panic(fmt.Errorf("malformed ldflags value for %s", path))
+		return nil, errors.New("scheme must be http or https")
 	}
 
 	// Require that the URL contained a host.
 	if parsed.Host == "" {
-		panic(fmt.Errorf("malformed ldflags value for %s", path))
+		return nil, errors.New("host must not be empty")
+	}
+
+	return parsed, nil
+}
+
+// mustExtra validates that the given value is a properly formatted set of
+// key/value pairs.
+func mustExtra(path, raw string) map[string]string {
+	parsed, err := parseExtra(raw)
+	if err != nil {
+		malformed(path, raw, err)
+
+		return nil
 	}
 
 	return parsed
 }
+
+// parseExtra parses the given value as key/value pairs, given either as
+// key=value separated by semicolons, as a JSON object, or as a base64 encoded
+// JSON object.
+func parseExtra(raw string) (map[string]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(raw, "{") {
+		return parseExtraJSON([]byte(raw))
+	}
+
+	// Base64 encoded JSON objects never contain "=" except as padding, so
+	// they cannot be confused with key=value pairs.
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if decoded, err := encoding.DecodeString(raw); err == nil && bytes.HasPrefix(bytes.TrimSpace(decoded), []byte("{")) {
+			return parseExtraJSON(decoded)
+		}
+	}
+
+	parsed := make(map[string]string)
+
+	for _, pair := range strings.Split(raw, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value but got %q", pair)
+		}
+
+		parsed[key] = strings.TrimSpace(value)
+	}
+
+	return parsed, nil
+}
+
+// parseExtraJSON parses the given JSON object as key/value pairs. Values that
+// are not strings are kept as their JSON text.
+func parseExtraJSON(body []byte) (map[string]string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, fmt.Errorf("malformed JSON object: %w", err)
+	}
+
+	parsed := make(map[string]string, len(object))
+
+	for key, value := range object {
+		var str string
+		if err := json.Unmarshal(value, &str); err == nil {
+			parsed[key] = str
+		} else {
+			parsed[key] = string(value)
+		}
+	}
+
+	return parsed, nil
+}
+
+// formatExtra formats the given key/value pairs as key=value separated by
+// semicolons, sorted by key.
+func formatExtra(extra map[string]string) string {
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+extra[key])
+	}
+
+	return strings.Join(pairs, ";")
+}
+
+// mustRepo validates that the given value is a properly formatted git remote
+// URL.
+func mustRepo(path, raw string) *u.URL {
+	parsed, err := parseRepo(raw)
+	if err != nil {
+		malformed(path, raw, err)
+
+		return nil
+	}
+
+	return parsed
+}
+
+// scpLikeRegex matches the scp-like syntax for git remote URLs, like
+// git@example.com:demo.git.
+var scpLikeRegex = regexp.MustCompile(`^(?:([^@/:]+)@)?([^@/:]+):(.+)$`)
+
+// parseRepo parses the given value as a git remote URL. The scp-like syntax
+// is converted to an ssh:// URL.
+func parseRepo(raw string) (*u.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	if !strings.Contains(raw, "://") {
+		matches := scpLikeRegex.FindStringSubmatch(raw)
+		if matches == nil {
+			return nil, errors.New("must be a URL or use the scp-like syntax")
+		}
+
+		parsed := &u.URL{
+			Scheme: "ssh",
+			Host:   matches[2],
+			Path:   "/" + strings.TrimPrefix(matches[3], "/"),
+		}
+
+		if matches[1] != "" {
+			parsed.User = u.User(matches[1])
+		}
+
+		return parsed, nil
+	}
+
+	parsed, err := u.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// Require a scheme that git can clone over the network.
+	switch parsed.Scheme {
+	case "http", "https", "ssh", "git", "git+ssh":
+	default:
+		return nil, errors.New("scheme must be http, https, ssh, or git")
+	}
+
+	// Require that the URL contained a host.
+	if parsed.Host == "" {
+		return nil, errors.New("host must not be empty")
+	}
+
+	// Credentials would be readable by anyone with the binary.
+	if _, ok := parsed.User.Password(); ok {
+		return nil, errors.New("must not contain a password")
+	}
+
+	return parsed, nil
+}
+
+// mustStrict validates that the given value is a properly formatted boolean.
+// Unlike mustBool, strict mode is enabled unless explicitly disabled.
+func mustStrict(_, raw string) bool {
+	if b, err := strconv.ParseBool(raw); err == nil {
+		return b
+	}
+
+	return true
+}
+
+// malformed handles a malformed ldflags value. In strict mode (the default)
+// it panics, otherwise the error is recorded and can be retrieved by calling
+// Errors.
+func malformed(path, raw string, err error) {
+	malformedErr := &MalformedError{
+		Path:  path,
+		Value: raw,
+		Err:   err,
+	}
+
+	if strictParsed {
+		panic(malformedErr)
+	}
+
+	recordError(malformedErr)
+}
+
+// validators maps the name of every variable to a function that reports if a
+// value for that variable is malformed.
+var validators = map[string]func(string) error{
+	"xiam.li/meta.author":           validString,
+	"xiam.li/meta.author_url":       validURL,
+	"xiam.li/meta.branch":           validString,
+	"xiam.li/meta.build_host":       validString,
+	"xiam.li/meta.build_id":         validString,
+	"xiam.li/meta.build_url":        validURL,
+	"xiam.li/meta.build_user":       validString,
+	"xiam.li/meta.ci":               validString,
+	"xiam.li/meta.commit_author":    validString,
+	"xiam.li/meta.commit_date":      validTime,
+	"xiam.li/meta.copyright":        validString,
+	"xiam.li/meta.date":             validTime,
+	"xiam.li/meta.desc":             validString,
+	"xiam.li/meta.dev":              validString,
+	"xiam.li/meta.dirty":            validString,
+	"xiam.li/meta.docs":             validURL,
+	"xiam.li/meta.extra":            validExtra,
+	"xiam.li/meta.license":          validString,
+	"xiam.li/meta.license_url":      validURL,
+	"xiam.li/meta.name":             validString,
+	"xiam.li/meta.note":             validString,
+	"xiam.li/meta.repo":             validRepo,
+	"xiam.li/meta.reproducible":     validString,
+	"xiam.li/meta.sha":              validSHA,
+	"xiam.li/meta.short_sha_length": validShortSHALength,
+	"xiam.li/meta.src":              validURL,
+	"xiam.li/meta.strict":           validString,
+	"xiam.li/meta.tag":              validString,
+	"xiam.li/meta.title":            validString,
+	"xiam.li/meta.url":              validURL,
+	"xiam.li/meta.version":          validString,
+}
+
+func validString(string) error {
+	return nil
+}
+
+func validExtra(raw string) error {
+	_, err := parseExtra(raw)
+
+	return err
+}
+
+func validRepo(raw string) error {
+	_, err := parseRepo(raw)
+
+	return err
+}
+
+func validSHA(raw string) error {
+	_, err := parseSHA(raw)
+
+	return err
+}
+
+func validShortSHALength(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	_, err := parseShortSHALength(raw)
+
+	return err
+}
+
+func validTime(raw string) error {
+	_, err := parseTime(raw)
+
+	return err
+}
+
+func validURL(raw string) error {
+	_, err := parseURL(raw)
+
+	return err
+}
+
+// Variables is the sorted list of names of every variable in this package.
+func Variables() []string {
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Validate reports if the given value for the named variable is malformed,
+// using the same rules that are applied when the value is given with ldflags.
+// Returns a *MalformedError for malformed values.
+func Validate(path, raw string) error {
+	validator, ok := validators[path]
+	if !ok {
+		return fmt.Errorf("unknown variable %s", path)
+	}
+
+	if err := validator(raw); err != nil {
+		return &MalformedError{
+			Path:  path,
+			Value: raw,
+			Err:   err,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,389 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a parsed semver version. See https://semver.org.
+type Semver struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	PreRelease string
+	Build      string
+}
+
+// ParseSemver parses the given value as a semver version. A leading "v" is
+// permitted, like in "v1.2.3".
+func ParseSemver(raw string) (Semver, error) {
+	major, minor, patch, preRelease, build := mustSemver("", raw)
+	if major == "" {
+		return Semver{}, fmt.Errorf("malformed semver version %q", raw)
+	}
+
+	var (
+		version = Semver{PreRelease: preRelease, Build: build}
+		err     error
+	)
+
+	if version.Major, err = strconv.ParseUint(major, 10, 64); err != nil {
+		return Semver{}, fmt.Errorf("malformed semver version %q: %w", raw, err)
+	}
+
+	if version.Minor, err = strconv.ParseUint(minor, 10, 64); err != nil {
+		return Semver{}, fmt.Errorf("malformed semver version %q: %w", raw, err)
+	}
+
+	if version.Patch, err = strconv.ParseUint(patch, 10, 64); err != nil {
+		return Semver{}, fmt.Errorf("malformed semver version %q: %w", raw, err)
+	}
+
+	return version, nil
+}
+
+// ParsedVersion is the parsed semver version for the application. Returns
+// false if the version is not set or is not a semver version. If the version
+// is the output of git describe, the tag is parsed, see DescribeTag.
+func ParsedVersion() (Semver, bool) {
+	version, err := ParseSemver(DescribeTag())
+	if err != nil {
+		return Semver{}, false
+	}
+
+	return version, true
+}
+
+// ParseDescribe splits the output of git describe, like v1.4.2-13-gbb2fecb,
+// into the tag, the number of commits since that tag, and the abbreviated SHA.
+// A value that is not git describe output is returned as the tag, so that
+// ParseSemver(tag) parses both plain and described versions.
+func ParseDescribe(raw string) (tag string, commits int, sha string) {
+	return parseDescribe(raw)
+}
+
+// String formats the version, without a leading "v".
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+
+	return s
+}
+
+// Compare returns -1, 0, or +1 depending on whether v has lower, equal, or
+// higher precedence than other. Build metadata is ignored.
+// See https://semver.org/#spec-item-11.
+func (v Semver) Compare(other Semver) int {
+	if c := compareUint(v.Major, other.Major); c != 0 {
+		return c
+	}
+
+	if c := compareUint(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+
+	if c := compareUint(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+// LessThan is whether v has lower precedence than other.
+func (v Semver) LessThan(other Semver) bool {
+	return v.Compare(other) < 0
+}
+
+// Satisfies is whether v satisfies the given constraint.
+// See ParseConstraint for the constraint syntax.
+func (v Semver) Satisfies(constraint string) (bool, error) {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(v), nil
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePreRelease compares two pre-release versions. A version without a
+// pre-release has higher precedence than one with a pre-release.
+func comparePreRelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A larger set of pre-release fields has a higher precedence, if all of
+	// the preceding identifiers are equal.
+	return compareUint(uint64(len(as)), uint64(len(bs)))
+}
+
+// compareIdentifier compares two pre-release identifiers. Numeric identifiers
+// are compared numerically, and always have lower precedence than
+// alphanumeric identifiers, which are compared lexically.
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// Constraint is a parsed version constraint.
+type Constraint struct {
+	// alternatives is a list of comparator sets, of which at least one must
+	// be satisfied. Every comparator in a set must be satisfied.
+	alternatives [][]comparator
+}
+
+// comparator is a single version comparison, like ">=1.2.0".
+type comparator struct {
+	op      string
+	version Semver
+}
+
+// ParseConstraint parses the given version constraint. A constraint is a list
+// of comparators separated by whitespace, all of which must be satisfied.
+// Multiple constraints may be joined with "||", of which at least one must be
+// satisfied. Supported comparators are:
+//
+//	=1.2.3   1.2.3   equal to 1.2.3
+//	!=1.2.3          not equal to 1.2.3
+//	>1.2.3   >=1.2.3 greater than (or equal to) 1.2.3
+//	<1.2.3   <=1.2.3 less than (or equal to) 1.2.3
+//	^1.2.3           compatible with 1.2.3, same as >=1.2.3 <2.0.0
+//	~1.2.3           approximately 1.2.3, same as >=1.2.3 <1.3.0
+//
+// Versions may be partial, like "^1.4" or ">=2", in which case the missing
+// minor and patch versions are treated as 0. For "^", the left-most non-zero
+// version may not change, so that "^0.2.3" is the same as ">=0.2.3 <0.3.0".
+// For "~", a missing minor version allows minor changes, so that "~1" is the
+// same as ">=1.0.0 <2.0.0". A partial version without an operator matches any
+// version with the given prefix, so that "1.4" is the same as "~1.4".
+//
+// An upper bound given with "<" (or implied by "^" and "~") also excludes the
+// pre-releases of that version, so that "<2.0.0" excludes "2.0.0-rc.1". Use
+// an explicit pre-release, like "<2.0.0-rc.2", to include some of them. An
+// operator may be separated from its version by whitespace.
+func ParseConstraint(raw string) (Constraint, error) {
+	var constraint Constraint
+
+	for _, alternative := range strings.Split(raw, "||") {
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			return Constraint{}, fmt.Errorf("malformed constraint %q", raw)
+		}
+
+		var comparators []comparator
+		for index := 0; index < len(fields); index++ {
+			// An operator may be separated from its version by whitespace,
+			// like ">= 1.2.0".
+			field := fields[index]
+			if isOperator(field) && index+1 < len(fields) {
+				index++
+				field += fields[index]
+			}
+
+			parsed, err := parseComparator(field)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("malformed constraint %q: %w", raw, err)
+			}
+
+			comparators = append(comparators, parsed...)
+		}
+
+		constraint.alternatives = append(constraint.alternatives, comparators)
+	}
+
+	return constraint, nil
+}
+
+// Check is whether the given version satisfies the constraint.
+func (c Constraint) Check(version Semver) bool {
+	for _, comparators := range c.alternatives {
+		if checkAll(comparators, version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkAll(comparators []comparator, version Semver) bool {
+	for _, comparator := range comparators {
+		if !comparator.check(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c comparator) check(version Semver) bool {
+	cmp := version.Compare(c.version)
+
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+// operators are the supported comparator operators. Longer operators are
+// listed first, so that ">=" isn't mistaken for ">".
+var operators = []string{">=", "<=", "!=", "=", ">", "<", "^", "~"}
+
+// isOperator is whether the given value is an operator on its own.
+func isOperator(raw string) bool {
+	for _, op := range operators {
+		if raw == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseComparator parses a single comparator, like ">=1.2.0" or "^1.4", into
+// one or more plain comparators.
+func parseComparator(raw string) ([]comparator, error) {
+	// Split the operator from the version.
+	var op string
+	for _, candidate := range operators {
+		if strings.HasPrefix(raw, candidate) {
+			op = candidate
+			raw = strings.TrimPrefix(raw, candidate)
+
+			break
+		}
+	}
+
+	version, parts, err := parsePartial(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case op == "^":
+		return []comparator{{">=", version}, {"<", caretUpper(version, parts)}}, nil
+	case op == "~", op == "" && parts < 3:
+		return []comparator{{">=", version}, {"<", tildeUpper(version, parts)}}, nil
+	case op == "":
+		return []comparator{{"=", version}}, nil
+	case op == "<" && version.PreRelease == "":
+		return []comparator{{"<", upper(version)}}, nil
+	default:
+		return []comparator{{op, version}}, nil
+	}
+}
+
+// parsePartial parses a full or partial version, and returns the number of
+// version parts (1 through 3) that were given.
+func parsePartial(raw string) (Semver, int, error) {
+	trimmed := strings.TrimPrefix(raw, "v")
+
+	// Partial versions only contain numbers, so anything with a pre-release
+	// or build must be a full version.
+	parts := strings.Split(trimmed, ".")
+	if len(parts) >= 3 || strings.ContainsAny(trimmed, "-+") {
+		version, err := ParseSemver(raw)
+
+		return version, 3, err //nolint:gomnd
+	}
+
+	// Pad the partial version with zeros, and parse it as a full version.
+	padded := trimmed + strings.Repeat(".0", 3-len(parts))
+
+	version, err := ParseSemver(padded)
+	if err != nil {
+		return Semver{}, 0, fmt.Errorf("malformed semver version %q", raw)
+	}
+
+	return version, len(parts), nil
+}
+
+// lowestPreRelease is the pre-release with the lowest possible precedence.
+const lowestPreRelease = "0"
+
+// upper returns the given version as an exclusive upper bound, which also
+// excludes the pre-releases of that version, so that "<2.0.0" (used as
+// "<2.0.0-0") excludes "2.0.0-rc.1". The same rule is used for every upper
+// bound, whether given with "<", "^", or "~".
+func upper(version Semver) Semver {
+	return Semver{Major: version.Major, Minor: version.Minor, Patch: version.Patch, PreRelease: lowestPreRelease}
+}
+
+// caretUpper returns the exclusive upper bound for a "^" comparator.
+func caretUpper(version Semver, parts int) Semver {
+	switch {
+	case version.Major != 0 || parts == 1:
+		return upper(Semver{Major: version.Major + 1})
+	case version.Minor != 0 || parts == 2: //nolint:gomnd
+		return upper(Semver{Minor: version.Minor + 1})
+	default:
+		return upper(Semver{Patch: version.Patch + 1})
+	}
+}
+
+// tildeUpper returns the exclusive upper bound for a "~" comparator.
+func tildeUpper(version Semver, parts int) Semver {
+	if parts == 1 {
+		return upper(Semver{Major: version.Major + 1})
+	}
+
+	return upper(Semver{Major: version.Major, Minor: version.Minor + 1})
+}
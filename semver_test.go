@@ -0,0 +1,280 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected Semver
+		err      bool
+	}{
+		{
+			input: "",
+			err:   true,
+		},
+		{
+			input: "latest",
+			err:   true,
+		},
+		{
+			input: "1.2",
+			err:   true,
+		},
+		{
+			input: "99999999999999999999.0.0",
+			err:   true,
+		},
+		{
+			input:    "1.2.3",
+			expected: Semver{Major: 1, Minor: 2, Patch: 3},
+		},
+		{
+			input:    "v1.2.3-rc.456+build.789",
+			expected: Semver{Major: 1, Minor: 2, Patch: 3, PreRelease: "rc.456", Build: "build.789"},
+		},
+	}
+
+	for i, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := ParseSemver(test.input)
+
+			switch {
+			case err == nil && test.err:
+				t.Fatal("expected an error")
+			case err != nil && !test.err:
+				t.Fatal(err)
+			case actual != test.expected:
+				t.Fatalf("expected %v but got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseDescribe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input           string
+		expectedTag     string
+		expectedCommits int
+		expectedSHA     string
+	}{
+		{input: "v1.4.2", expectedTag: "v1.4.2"},
+		{input: "v1.4.2-rc.1", expectedTag: "v1.4.2-rc.1"},
+		{input: "v1.4.2-dirty", expectedTag: "v1.4.2"},
+		{input: "v1.4.2-13-gbb2fecb", expectedTag: "v1.4.2", expectedCommits: 13, expectedSHA: "bb2fecb"},
+		{input: "v1.4.2-rc.1-2-gbb2fecb-dirty", expectedTag: "v1.4.2-rc.1", expectedCommits: 2, expectedSHA: "bb2fecb"},
+		{input: "latest", expectedTag: "latest"},
+		{input: ""},
+	}
+
+	for i, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			tag, commits, sha := ParseDescribe(test.input)
+			if tag != test.expectedTag || commits != test.expectedCommits || sha != test.expectedSHA {
+				t.Fatalf("expected %q, %d, %q but got %q, %d, %q",
+					test.expectedTag, test.expectedCommits, test.expectedSHA, tag, commits, sha)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	t.Parallel()
+
+	// Each version has a lower precedence than the next.
+	// See https://semver.org/#spec-item-11.
+	ordered := []string{
+		"0.9.9",
+		"1.0.0-0",
+		"1.0.0-2",
+		"1.0.0-11",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+		"10.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := ParseSemver(ordered[i])
+			b, _ := ParseSemver(ordered[j])
+
+			expected := compareUint(uint64(i), uint64(j))
+			if actual := a.Compare(b); actual != expected {
+				t.Fatalf("expected %s compared to %s to be %d but got %d", a, b, expected, actual)
+			}
+
+			if actual := a.LessThan(b); actual != (i < j) {
+				t.Fatalf("expected %s less than %s to be %v but got %v", a, b, i < j, actual)
+			}
+		}
+	}
+
+	// Build metadata is ignored.
+	a, _ := ParseSemver("1.0.0+build.1")
+	b, _ := ParseSemver("1.0.0+build.2")
+
+	if a.Compare(b) != 0 {
+		t.Fatalf("expected %s to equal %s", a, b)
+	}
+}
+
+func TestSemverSatisfies(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		matches    []string
+		misses     []string
+		err        bool
+	}{
+		{
+			constraint: ">=1.2.0 <2.0.0",
+			matches:    []string{"1.2.0", "1.9.9"},
+			misses:     []string{"1.1.9", "2.0.0"},
+		},
+		{
+			constraint: ">=1.2.3 <2.0.0",
+			matches:    []string{"1.2.3", "1.9.9-rc.1"},
+			misses:     []string{"2.0.0-rc.1", "2.0.0"},
+		},
+		{
+			constraint: "^1.2.3",
+			matches:    []string{"1.2.3", "1.9.9-rc.1"},
+			misses:     []string{"2.0.0-rc.1", "2.0.0"},
+		},
+		{
+			constraint: ">=1.2.3 <1.3.0",
+			matches:    []string{"1.2.3", "1.2.9"},
+			misses:     []string{"1.3.0-rc.1", "1.3.0"},
+		},
+		{
+			constraint: "~1.2.3",
+			matches:    []string{"1.2.3", "1.2.9"},
+			misses:     []string{"1.3.0-rc.1", "1.3.0"},
+		},
+		{
+			constraint: "<2.0.0-rc.2",
+			matches:    []string{"1.9.9", "2.0.0-rc.1"},
+			misses:     []string{"2.0.0-rc.2", "2.0.0"},
+		},
+		{
+			constraint: ">= 1.2.0 < 2",
+			matches:    []string{"1.2.0", "1.9.9"},
+			misses:     []string{"1.1.9", "2.0.0-rc.1"},
+		},
+		{
+			constraint: "^1.4",
+			matches:    []string{"1.4.0", "1.99.0"},
+			misses:     []string{"1.3.9", "2.0.0", "2.0.0-rc.1"},
+		},
+		{
+			constraint: "^0.2.3",
+			matches:    []string{"0.2.3", "0.2.9"},
+			misses:     []string{"0.2.2", "0.3.0"},
+		},
+		{
+			constraint: "^0.0.3",
+			matches:    []string{"0.0.3"},
+			misses:     []string{"0.0.4"},
+		},
+		{
+			constraint: "~1.4.2",
+			matches:    []string{"1.4.2", "1.4.9"},
+			misses:     []string{"1.4.1", "1.5.0"},
+		},
+		{
+			constraint: "~1",
+			matches:    []string{"1.0.0", "1.9.0"},
+			misses:     []string{"0.9.0", "2.0.0"},
+		},
+		{
+			constraint: "1.4",
+			matches:    []string{"1.4.0", "1.4.9"},
+			misses:     []string{"1.5.0"},
+		},
+		{
+			constraint: "v1.2.3",
+			matches:    []string{"1.2.3", "1.2.3+build.1"},
+			misses:     []string{"1.2.4", "1.2.3-rc.1"},
+		},
+		{
+			constraint: "!=1.2.3",
+			matches:    []string{"1.2.4"},
+			misses:     []string{"1.2.3"},
+		},
+		{
+			constraint: "<1.0.0 || >=2.0.0 <=2.1.0",
+			matches:    []string{"0.1.0", "2.0.0", "2.1.0"},
+			misses:     []string{"1.0.0", "2.1.1"},
+		},
+		{
+			constraint: "",
+			err:        true,
+		},
+		{
+			constraint: ">=latest",
+			err:        true,
+		},
+		{
+			constraint: "^1.2 ||",
+			err:        true,
+		},
+		{
+			constraint: ">=",
+			err:        true,
+		},
+	}
+
+	for i, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ParseConstraint(test.constraint); (err != nil) != test.err {
+				t.Fatalf("expected error %v but got %v", test.err, err)
+			}
+
+			for _, raw := range test.matches {
+				version, _ := ParseSemver(raw)
+				if ok, _ := version.Satisfies(test.constraint); !ok {
+					t.Fatalf("expected %s to satisfy %q", raw, test.constraint)
+				}
+			}
+
+			for _, raw := range test.misses {
+				version, _ := ParseSemver(raw)
+				if ok, _ := version.Satisfies(test.constraint); ok {
+					t.Fatalf("expected %s to not satisfy %q", raw, test.constraint)
+				}
+			}
+		})
+	}
+}
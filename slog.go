@@ -0,0 +1,48 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+//go:build go1.21
+
+package meta
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// LogValue implements slog.LogValuer, so that the snapshot is logged as a
+// group of attributes, named after the fields returned by Fields. Fields that
+// are not set are omitted. Requires Go 1.21 or later.
+//
+//	slog.Info("starting", "build", meta.Get())
+func (i Info) LogValue() slog.Value {
+	var attrs []slog.Attr
+
+	for _, field := range i.Fields() {
+		// The extra key/value pairs are logged as a nested group instead.
+		if field.Value == "" || field.Key == "extra" {
+			continue
+		}
+
+		attrs = append(attrs, slog.String(field.Key, field.Value))
+	}
+
+	if len(i.Extra) > 0 {
+		keys := make([]string, 0, len(i.Extra))
+		for key := range i.Extra {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		extra := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			extra = append(extra, slog.String(key, i.Extra[key]))
+		}
+
+		attrs = append(attrs, slog.Attr{Key: "extra", Value: slog.GroupValue(extra...)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
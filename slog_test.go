@@ -0,0 +1,52 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+//go:build go1.21
+
+package meta
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestInfoLogValue(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	info := Info{
+		Name:    "demo-app",
+		Date:    &date,
+		Version: "v1.2.3",
+		Extra:   map[string]string{"tier": "enterprise", "env": "production"},
+	}
+
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && (attr.Key == slog.TimeKey || attr.Key == slog.LevelKey) {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	}))
+
+	logger.Info("starting", "build", info)
+
+	expected := "msg=starting " +
+		"build.name=demo-app " +
+		"build.date=2019-08-23T18:00:00Z " +
+		"build.reproducible=false " +
+		"build.development=false " +
+		"build.dirty=false " +
+		"build.version=v1.2.3 " +
+		"build.extra.env=production " +
+		"build.extra.tier=enterprise\n"
+
+	equalString(t, expected, buffer.String())
+}
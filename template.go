@@ -0,0 +1,107 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// templates are the built-in named templates, which can be used in place of
+// a template with Format and Render.
+var templates = map[string]string{
+	// A single line containing the name and version, like:
+	//
+	//	demo-app v1.2.3
+	"short": `{{ join " " .Name .Version }}` + "\n",
+
+	// A footer for --version or --help output, like:
+	//
+	//	Demo Application v1.2.3 (bb2fecb)
+	//	A super simple demonstration application
+	//	Copyright 2021 Jane Doe
+	//	License MIT <https://example.com/demo/LICENSE.txt>
+	//	https://example.com/demo
+	"long": `{{ join " " (or .Title .Name) .Version }}{{ with .ShortSHA }} ({{ . }}{{ if $.Dirty }}-dirty{{ end }}){{ end }}
+{{ with .Description }}{{ . }}
+{{ end }}{{ with .Copyright }}Copyright {{ . }}
+{{ end }}{{ with .License }}License {{ . }}{{ with $.LicenseURL }} <{{ . }}>{{ end }}
+{{ end }}{{ with .URL }}{{ . }}
+{{ end }}`,
+
+	// The format recommended for --version output by the GNU coding standards,
+	// see https://www.gnu.org/prep/standards/html_node/_002d_002dversion.html.
+	// Like:
+	//
+	//	demo-app (Demo Application) v1.2.3
+	//	Copyright (C) 2021 Jane Doe
+	//	License MIT: <https://example.com/demo/LICENSE.txt>
+	"gnu": `{{ .Name }}{{ with .Title }} ({{ . }}){{ end }} {{ .Version }}
+{{ with .Copyright }}Copyright (C) {{ . }}
+{{ end }}{{ with .License }}License {{ . }}{{ with $.LicenseURL }}: <{{ . }}>{{ end }}
+{{ end }}`,
+
+	// The name and version in the format used by npm, like:
+	//
+	//	demo-app@v1.2.3
+	"npm-style": `{{ .Name }}@{{ .Version }}` + "\n",
+}
+
+// templateFuncs are additional functions available to templates.
+var templateFuncs = template.FuncMap{
+	// join joins all non-empty values with the given separator.
+	"join": func(sep string, values ...string) string {
+		var nonEmpty []string
+		for _, value := range values {
+			if value != "" {
+				nonEmpty = append(nonEmpty, value)
+			}
+		}
+
+		return strings.Join(nonEmpty, sep)
+	},
+}
+
+// Format executes the given text/template, using the metadata snapshot (see
+// Info) as data, and returns the result. The name of a built-in template may
+// be given instead of a template:
+//
+//	short      demo-app v1.2.3
+//	long       multiple lines with the title, version, copyright, and license
+//	gnu        the --version format recommended by the GNU coding standards
+//	npm-style  demo-app@v1.2.3
+//
+// Templates can also use the join function, which joins all non-empty values
+// with a separator, like {{ join " " .Name .Version }}.
+func Format(tmpl string) (string, error) {
+	var builder strings.Builder
+	if err := Render(&builder, tmpl); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// Render executes the given text/template, using the metadata snapshot (see
+// Info) as data, and writes the result to the given writer. See Format.
+func Render(w io.Writer, tmpl string) error {
+	return render(w, tmpl, Get())
+}
+
+// render executes the given template or named template with the given
+// snapshot as data.
+func render(w io.Writer, tmpl string, info Info) error {
+	if builtin, ok := templates[tmpl]; ok {
+		tmpl = builtin
+	}
+
+	parsed, err := template.New("").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	return parsed.Execute(w, info)
+}
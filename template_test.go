@@ -0,0 +1,130 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	full := Info{
+		Name:        "demo-app",
+		Title:       "Demo Application",
+		Description: "A super simple demonstration application",
+		Copyright:   "2021 Jane Doe",
+		License:     "MIT",
+		LicenseURL:  "https://example.com/demo/LICENSE.txt",
+		URL:         "https://example.com/demo",
+		Version:     "v1.2.3",
+		ShortSHA:    "bb2fecb",
+	}
+
+	minimal := Info{
+		Name:    "demo-app",
+		Version: "v1.2.3",
+	}
+
+	tests := []struct {
+		tmpl     string
+		info     Info
+		expected string
+		err      bool
+	}{
+		{
+			tmpl:     "short",
+			info:     full,
+			expected: "demo-app v1.2.3\n",
+		},
+		{
+			tmpl:     "short",
+			info:     Info{Version: "v1.2.3"},
+			expected: "v1.2.3\n",
+		},
+		{
+			tmpl: "long",
+			info: full,
+			expected: "Demo Application v1.2.3 (bb2fecb)\n" +
+				"A super simple demonstration application\n" +
+				"Copyright 2021 Jane Doe\n" +
+				"License MIT <https://example.com/demo/LICENSE.txt>\n" +
+				"https://example.com/demo\n",
+		},
+		{
+			tmpl:     "long",
+			info:     Info{Name: "demo-app", ShortSHA: "bb2fecb", Dirty: true},
+			expected: "demo-app (bb2fecb-dirty)\n",
+		},
+		{
+			tmpl:     "long",
+			info:     minimal,
+			expected: "demo-app v1.2.3\n",
+		},
+		{
+			tmpl: "gnu",
+			info: full,
+			expected: "demo-app (Demo Application) v1.2.3\n" +
+				"Copyright (C) 2021 Jane Doe\n" +
+				"License MIT: <https://example.com/demo/LICENSE.txt>\n",
+		},
+		{
+			tmpl:     "gnu",
+			info:     minimal,
+			expected: "demo-app v1.2.3\n",
+		},
+		{
+			tmpl:     "npm-style",
+			info:     full,
+			expected: "demo-app@v1.2.3\n",
+		},
+		{
+			tmpl:     `{{ .Name }} is licensed under {{ .License }}`,
+			info:     full,
+			expected: "demo-app is licensed under MIT",
+		},
+		{
+			tmpl: `{{ .Name `,
+			err:  true,
+		},
+		{
+			tmpl: `{{ .Unknown }}`,
+			err:  true,
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+			err := render(&builder, test.tmpl, test.info)
+
+			switch {
+			case err != nil && !test.err:
+				t.Fatal(err)
+			case err == nil && test.err:
+				t.Fatal("expected an error")
+			case err == nil:
+				equalString(t, test.expected, builder.String())
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	actual, err := Format("{{ .Version }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, Version(), actual)
+}
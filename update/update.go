@@ -0,0 +1,422 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+// Package update checks whether a newer version of the application has been
+// released, by comparing the application version against a release feed.
+//
+// Two kinds of release feed are supported. The first is the GitHub releases
+// API (https://docs.github.com/en/rest/releases/releases), which is used when
+// the application source URL points at a GitHub repository. The second is a
+// plain JSON manifest, which by default is expected at /releases.json
+// relative to the application homepage or source URL:
+//
+//	{
+//	  "releases": [
+//	    {"version": "v1.2.3", "url": "https://example.com/demo/v1.2.3"},
+//	    {"version": "v1.3.0-rc.1", "prerelease": true}
+//	  ]
+//	}
+package update
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	u "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"xiam.li/meta"
+)
+
+// Default values for Checker fields.
+const (
+	DefaultBaseURL  = "https://api.github.com"
+	DefaultTimeout  = 10 * time.Second
+	DefaultCacheTTL = 24 * time.Hour
+)
+
+// Release is a single release from a release feed.
+type Release struct {
+	// Version is the release version, like v1.2.3.
+	Version string `json:"version"`
+
+	// PreRelease is whether the release is a pre-release. Releases with a
+	// semver pre-release version are always considered pre-releases.
+	PreRelease bool `json:"prerelease,omitempty"`
+
+	// URL is a page where the release can be downloaded, if known.
+	URL string `json:"url,omitempty"`
+}
+
+// Result is the result of an update check.
+type Result struct {
+	// Current is the version of the running application.
+	Current meta.Semver
+
+	// LatestStable is the newest stable release, or nil if there is none.
+	LatestStable *Release
+
+	// LatestPreRelease is the newest pre-release, or nil if there is none.
+	LatestPreRelease *Release
+
+	// CheckedAt is the time that the release feed was fetched. May be in the
+	// past if the release feed was read from the cache.
+	CheckedAt time.Time
+}
+
+// UpdateAvailable is whether a stable release newer than the current version
+// exists.
+func (r *Result) UpdateAvailable() bool {
+	return newer(r.LatestStable, r.Current)
+}
+
+// PreReleaseAvailable is whether a pre-release newer than both the current
+// version and the latest stable release exists.
+func (r *Result) PreReleaseAvailable() bool {
+	if !newer(r.LatestPreRelease, r.Current) {
+		return false
+	}
+
+	if r.LatestStable == nil {
+		return true
+	}
+
+	stable, _ := meta.ParseSemver(r.LatestStable.Version)
+
+	return newer(r.LatestPreRelease, stable)
+}
+
+// newer is whether the given release is newer than the given version.
+func newer(release *Release, version meta.Semver) bool {
+	if release == nil {
+		return false
+	}
+
+	parsed, err := meta.ParseSemver(release.Version)
+
+	return err == nil && version.LessThan(parsed)
+}
+
+// Checker checks a release feed for newer versions. The zero value checks for
+// newer versions of the running application.
+type Checker struct {
+	// Current is the version to compare against. Defaults to
+	// meta.DescribeTag(), which is meta.Version() without any commit count
+	// and abbreviated SHA from git describe.
+	Current string
+
+	// FeedURL is the URL of the release feed. Defaults to the GitHub releases
+	// API if meta.Source() is a GitHub repository, or releases.json relative
+	// to meta.URL() or meta.Source() otherwise.
+	FeedURL string
+
+	// BaseURL is the base URL of the GitHub API, used when deriving FeedURL.
+	// Defaults to DefaultBaseURL. Can be set to the URL of an httptest.Server
+	// for testing.
+	BaseURL string
+
+	// Client is used to fetch the release feed. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// Timeout limits how long fetching the release feed may take. Defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+
+	// CacheFile is the path of a file where the release feed is cached. The
+	// cache is disabled if empty. Failures to write the cache are ignored.
+	CacheFile string
+
+	// CacheTTL is how long the cached release feed is used before fetching it
+	// again. Defaults to DefaultCacheTTL.
+	CacheTTL time.Duration
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Check checks for newer versions of the running application, using the
+// default Checker.
+func Check(ctx context.Context) (*Result, error) {
+	return (&Checker{}).Check(ctx)
+}
+
+// Check fetches the release feed (or reads it from the cache), and compares
+// it against the current version.
+func (c *Checker) Check(ctx context.Context) (*Result, error) {
+	current, err := meta.ParseSemver(c.current())
+	if err != nil {
+		return nil, fmt.Errorf("current version: %w", err)
+	}
+
+	feedURL, err := c.feedURL()
+	if err != nil {
+		return nil, err
+	}
+
+	cached, ok := c.readCache(feedURL)
+	if !ok {
+		releases, err := c.fetch(ctx, feedURL)
+		if err != nil {
+			return nil, err
+		}
+
+		cached = cache{
+			FeedURL:   feedURL,
+			CheckedAt: c.now(),
+			Releases:  releases,
+		}
+
+		c.writeCache(cached)
+	}
+
+	result := Result{
+		Current:   current,
+		CheckedAt: cached.CheckedAt,
+	}
+
+	result.LatestStable, result.LatestPreRelease = latest(cached.Releases)
+
+	return &result, nil
+}
+
+func (c *Checker) current() string {
+	if c.Current != "" {
+		return c.Current
+	}
+
+	return meta.DescribeTag()
+}
+
+func (c *Checker) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+
+	return time.Now()
+}
+
+func (c *Checker) feedURL() (string, error) {
+	if c.FeedURL != "" {
+		return c.FeedURL, nil
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return deriveFeedURL(meta.Source(), meta.URL(), baseURL)
+}
+
+// deriveFeedURL returns the URL of the release feed for the given source and
+// homepage URLs.
+func deriveFeedURL(source, homepage *u.URL, baseURL string) (string, error) {
+	// Use the GitHub releases API for GitHub repositories.
+	if source != nil && source.Host == "github.com" {
+		parts := strings.Split(strings.Trim(strings.TrimSuffix(source.Path, ".git"), "/"), "/")
+		if len(parts) >= 2 { //nolint:gomnd
+			return strings.TrimSuffix(baseURL, "/") + "/repos/" + parts[0] + "/" + parts[1] + "/releases", nil
+		}
+	}
+
+	// Otherwise, use a manifest relative to the homepage or source.
+	for _, base := range []*u.URL{homepage, source} {
+		if base != nil {
+			feed := *base
+			feed.Path = strings.TrimSuffix(strings.TrimSuffix(feed.Path, ".git"), "/") + "/releases.json"
+
+			return feed.String(), nil
+		}
+	}
+
+	return "", errors.New("no release feed URL, as neither a source nor homepage URL are set")
+}
+
+// fetch fetches and parses the release feed.
+func (c *Checker) fetch(ctx context.Context, feedURL string) ([]Release, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", feedURL, response.Status)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := parseFeed(body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", feedURL, err)
+	}
+
+	return releases, nil
+}
+
+// githubRelease is a single release from the GitHub releases API.
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	PreRelease bool   `json:"prerelease"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// manifest is a plain JSON release manifest.
+type manifest struct {
+	Releases []Release `json:"releases"`
+}
+
+// parseFeed parses either a GitHub releases API response (a JSON array) or a
+// plain JSON manifest (a JSON object).
+func parseFeed(body []byte) ([]Release, error) {
+	body = bytes.TrimSpace(body)
+
+	if bytes.HasPrefix(body, []byte("[")) {
+		var githubReleases []githubRelease
+		if err := json.Unmarshal(body, &githubReleases); err != nil {
+			return nil, err
+		}
+
+		releases := make([]Release, 0, len(githubReleases))
+		for _, release := range githubReleases {
+			if release.Draft {
+				continue
+			}
+
+			releases = append(releases, Release{
+				Version:    release.TagName,
+				PreRelease: release.PreRelease,
+				URL:        release.HTMLURL,
+			})
+		}
+
+		return releases, nil
+	}
+
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+
+	return m.Releases, nil
+}
+
+// latest returns the newest stable release and newest pre-release. Releases
+// that are not semver versions are ignored.
+func latest(releases []Release) (*Release, *Release) {
+	var (
+		stable, preRelease               *Release
+		stableVersion, preReleaseVersion meta.Semver
+	)
+
+	for i := range releases {
+		release := releases[i]
+
+		version, err := meta.ParseSemver(release.Version)
+		if err != nil {
+			continue
+		}
+
+		if release.PreRelease || version.PreRelease != "" {
+			release.PreRelease = true
+			if preRelease == nil || preReleaseVersion.LessThan(version) {
+				preRelease, preReleaseVersion = &release, version
+			}
+
+			continue
+		}
+
+		if stable == nil || stableVersion.LessThan(version) {
+			stable, stableVersion = &release, version
+		}
+	}
+
+	return stable, preRelease
+}
+
+// cache is the content of the cache file.
+type cache struct {
+	FeedURL   string    `json:"feed_url"`
+	CheckedAt time.Time `json:"checked_at"`
+	Releases  []Release `json:"releases"`
+}
+
+// readCache returns the cached release feed, if it exists, is for the given
+// feed URL, and has not expired.
+func (c *Checker) readCache(feedURL string) (cache, bool) {
+	if c.CacheFile == "" {
+		return cache{}, false
+	}
+
+	body, err := os.ReadFile(c.CacheFile)
+	if err != nil {
+		return cache{}, false
+	}
+
+	var cached cache
+	if err := json.Unmarshal(body, &cached); err != nil {
+		return cache{}, false
+	}
+
+	ttl := c.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	if cached.FeedURL != feedURL || c.now().Sub(cached.CheckedAt) > ttl {
+		return cache{}, false
+	}
+
+	return cached, true
+}
+
+// writeCache writes the given release feed to the cache file.
+func (c *Checker) writeCache(cached cache) {
+	if c.CacheFile == "" {
+		return
+	}
+
+	body, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.CacheFile), 0o755); err != nil { //nolint:gomnd
+		return
+	}
+
+	os.WriteFile(c.CacheFile, body, 0o644) //nolint:errcheck,gomnd
+}
@@ -0,0 +1,298 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package update
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const githubFeed = `[
+	{"tag_name": "v1.4.0-rc.1", "prerelease": true, "html_url": "https://github.com/example/demo/releases/v1.4.0-rc.1"},
+	{"tag_name": "v1.5.0", "draft": true},
+	{"tag_name": "v1.3.0", "html_url": "https://github.com/example/demo/releases/v1.3.0"},
+	{"tag_name": "v1.2.0"},
+	{"tag_name": "nightly"}
+]`
+
+const manifestFeed = `{
+	"releases": [
+		{"version": "v1.2.3"},
+		{"version": "v1.3.0-beta.1"}
+	]
+}`
+
+func TestCheck(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	tests := []struct {
+		current                     string
+		feed                        string
+		expectedStable              string
+		expectedPreRelease          string
+		expectedUpdateAvailable     bool
+		expectedPreReleaseAvailable bool
+		err                         bool
+	}{
+		{
+			current:                     "v1.2.0",
+			feed:                        githubFeed,
+			expectedStable:              "v1.3.0",
+			expectedPreRelease:          "v1.4.0-rc.1",
+			expectedUpdateAvailable:     true,
+			expectedPreReleaseAvailable: true,
+		},
+		{
+			current:                     "v1.3.0",
+			feed:                        githubFeed,
+			expectedStable:              "v1.3.0",
+			expectedPreRelease:          "v1.4.0-rc.1",
+			expectedPreReleaseAvailable: true,
+		},
+		{
+			current:            "v1.4.0",
+			feed:               githubFeed,
+			expectedStable:     "v1.3.0",
+			expectedPreRelease: "v1.4.0-rc.1",
+		},
+		{
+			current:                     "1.2.3",
+			feed:                        manifestFeed,
+			expectedStable:              "v1.2.3",
+			expectedPreRelease:          "v1.3.0-beta.1",
+			expectedPreReleaseAvailable: true,
+		},
+		{
+			current: "latest",
+			feed:    manifestFeed,
+			err:     true,
+		},
+		{
+			current: "v1.2.3",
+			feed:    "not json",
+			err:     true,
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, test.feed)
+			}))
+			defer server.Close()
+
+			checker := Checker{
+				Current: test.current,
+				FeedURL: server.URL,
+			}
+
+			result, err := checker.Check(context.Background())
+
+			switch {
+			case err != nil && !test.err:
+				t.Fatal(err)
+			case err == nil && test.err:
+				t.Fatal("expected an error")
+			case err != nil:
+				return
+			}
+
+			equalString(t, test.expectedStable, result.LatestStable.Version)
+			equalString(t, test.expectedPreRelease, result.LatestPreRelease.Version)
+
+			if result.UpdateAvailable() != test.expectedUpdateAvailable {
+				t.Fatalf("expected update available %v", test.expectedUpdateAvailable)
+			}
+
+			if result.PreReleaseAvailable() != test.expectedPreReleaseAvailable {
+				t.Fatalf("expected pre-release available %v", test.expectedPreReleaseAvailable)
+			}
+		})
+	}
+}
+
+func TestCheckGitHub(t *testing.T) {
+	t.Parallel()
+
+	paths := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+		fmt.Fprint(w, githubFeed)
+	}))
+	defer server.Close()
+
+	source, _ := u.Parse("https://github.com/example/demo.git")
+
+	feedURL, err := deriveFeedURL(source, nil, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checker := Checker{
+		Current: "v1.0.0",
+		FeedURL: feedURL,
+	}
+
+	if _, err := checker.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	equalString(t, "/repos/example/demo/releases", <-paths)
+}
+
+func TestCheckStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	checker := Checker{
+		Current: "v1.0.0",
+		FeedURL: server.URL,
+	}
+
+	if _, err := checker.Check(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	checker := Checker{
+		Current: "v1.0.0",
+		FeedURL: server.URL,
+		Timeout: 10 * time.Millisecond,
+	}
+
+	if _, err := checker.Check(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, manifestFeed)
+	}))
+	defer server.Close()
+
+	now := time.Date(2019, 8, 23, 18, 0, 0, 0, time.UTC)
+	checker := Checker{
+		Current:   "v1.0.0",
+		FeedURL:   server.URL,
+		CacheFile: filepath.Join(t.TempDir(), "cache", "update.json"),
+		CacheTTL:  time.Hour,
+		Now:       func() time.Time { return now },
+	}
+
+	// The first check fetches the feed, and the second reads the cache.
+	for i := 0; i < 2; i++ {
+		result, err := checker.Check(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !result.CheckedAt.Equal(now) {
+			t.Fatalf("expected %v but got %v", now, result.CheckedAt)
+		}
+	}
+
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("expected 1 request but got %d", requests)
+	}
+
+	// Once the cache expires, the feed is fetched again.
+	checker.Now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, err := checker.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Fatalf("expected 2 requests but got %d", requests)
+	}
+}
+
+func TestDeriveFeedURL(t *testing.T) {
+	t.Parallel()
+
+	parse := func(raw string) *u.URL {
+		parsed, _ := u.Parse(raw)
+
+		return parsed
+	}
+
+	tests := []struct {
+		source   *u.URL
+		homepage *u.URL
+		expected string
+		err      bool
+	}{
+		{
+			source:   parse("https://github.com/example/demo"),
+			expected: DefaultBaseURL + "/repos/example/demo/releases",
+		},
+		{
+			source:   parse("https://example.com/git/demo.git"),
+			expected: "https://example.com/git/demo/releases.json",
+		},
+		{
+			source:   parse("https://example.com/git/demo.git"),
+			homepage: parse("https://example.com/demo/"),
+			expected: "https://example.com/demo/releases.json",
+		},
+		{
+			err: true,
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := deriveFeedURL(test.source, test.homepage, DefaultBaseURL)
+
+			switch {
+			case err != nil && !test.err:
+				t.Fatal(err)
+			case err == nil && test.err:
+				t.Fatal("expected an error")
+			}
+
+			equalString(t, test.expected, actual)
+		})
+	}
+}
+
+func equalString(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	if actual != expected {
+		t.Fatalf("expected %q but got %q", expected, actual)
+	}
+}
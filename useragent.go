@@ -0,0 +1,119 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"strings"
+)
+
+// UserAgent is a User-Agent header value for the application, like:
+//
+//	demo-app/v1.2.3 (linux; amd64) go/1.21.4
+//
+// See Info.UserAgent.
+func UserAgent() string {
+	return Get().UserAgent()
+}
+
+// UserAgent is a User-Agent header value for the snapshot, made of product
+// tokens as described by RFC 9110, section 10.1.5. The first product is the
+// application name and version, followed by the operating system and
+// architecture as a comment, and then the Go version. Characters that are not
+// allowed in a token are replaced with an underscore. The application product
+// is omitted if the name is not set.
+func (i Info) UserAgent() string {
+	var products []string
+
+	if i.Name != "" {
+		products = append(products, product(i.Name, i.Version))
+	}
+
+	if i.Go != "" {
+		products = append(products, product("go", goVersion(i.Go)))
+	}
+
+	var details []string
+	for _, detail := range []string{i.OS, i.Arch} {
+		if detail != "" {
+			details = append(details, commentText(detail))
+		}
+	}
+
+	// The comment describes the first product, so directly follows it.
+	if len(products) > 0 && len(details) > 0 {
+		products[0] += " (" + strings.Join(details, "; ") + ")"
+	}
+
+	return strings.Join(products, " ")
+}
+
+// product formats the given name and version as a product token, like
+// name/version. The version is omitted if it is not set.
+func product(name, version string) string {
+	if version == "" {
+		return token(name)
+	}
+
+	return token(name) + "/" + token(version)
+}
+
+// goVersion returns the version number from a Go runtime version, like 1.21.4
+// from go1.21.4. Development versions of Go are returned as devel.
+func goVersion(raw string) string {
+	if strings.HasPrefix(raw, "go") {
+		version, _, _ := strings.Cut(strings.TrimPrefix(raw, "go"), " ")
+
+		return version
+	}
+
+	if strings.HasPrefix(raw, "devel") {
+		return "devel"
+	}
+
+	return raw
+}
+
+// token replaces all characters in the given string that are not allowed in
+// an RFC 9110 token with an underscore.
+func token(raw string) string {
+	return strings.Map(func(r rune) rune {
+		if isTokenChar(r) {
+			return r
+		}
+
+		return '_'
+	}, raw)
+}
+
+// isTokenChar is whether the given character is an RFC 9110 tchar.
+func isTokenChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	default:
+		return strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+	}
+}
+
+// commentText escapes the given string for use in an RFC 9110 comment.
+// Parentheses and backslashes are escaped with a backslash, and control and
+// non-ASCII characters are replaced with an underscore.
+func commentText(raw string) string {
+	var builder strings.Builder
+
+	for _, r := range raw {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r == '\t' || (r >= ' ' && r < 0x7f):
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+
+	return builder.String()
+}
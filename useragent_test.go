@@ -0,0 +1,60 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+
+package meta
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInfoUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		info     Info
+		expected string
+	}{
+		{
+			info:     Info{Name: "demo-app", Version: "v1.2.3", OS: "linux", Arch: "amd64", Go: "go1.21.4"},
+			expected: "demo-app/v1.2.3 (linux; amd64) go/1.21.4",
+		},
+		{
+			info:     Info{Name: "demo-app", OS: "linux", Arch: "amd64", Go: "go1.21.4"},
+			expected: "demo-app (linux; amd64) go/1.21.4",
+		},
+		{
+			info:     Info{Name: "Demo App", Version: "v1.2.3-rc.1+build/5", Go: "go1.21.4"},
+			expected: "Demo_App/v1.2.3-rc.1+build_5 go/1.21.4",
+		},
+		{
+			info:     Info{Name: "démo", OS: "plan9 (x)", Arch: "arm\\64", Go: "go1.21.4"},
+			expected: `d_mo (plan9 \(x\); arm\\64) go/1.21.4`,
+		},
+		{
+			info:     Info{OS: "linux", Arch: "amd64", Go: "devel go1.22-3f1c9a2 Tue Oct 3 12:00:00 2023 +0000"},
+			expected: "go/devel (linux; amd64)",
+		},
+		{
+			info:     Info{},
+			expected: "",
+		},
+	}
+
+	for index, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprint(index), func(t *testing.T) {
+			t.Parallel()
+
+			equalString(t, test.expected, test.info.UserAgent())
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	t.Parallel()
+
+	equalString(t, Get().UserAgent(), UserAgent())
+}